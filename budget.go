@@ -0,0 +1,68 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrBudgetExhausted = errors.New("budget exhausted")
+
+// WithBudget sets a deadline for the whole run. Handlers registered with
+// WithBudgetShare or WithMinBudget get their own deadline out of what is left
+// of the budget once their declared dependencies are done.
+func WithBudget[S Store](budget time.Duration) RunnerOption[S] {
+	return func(r *Runner[S]) {
+		r.budget = budget
+	}
+}
+
+// WithBudgetShare limits the handler to a share in (0, 1] of the remaining budget.
+func WithBudgetShare[S Store](share float64) Option[S] {
+	return Option[S]{
+		descriptor: OptionDescriptor{Kind: OptionBudgetShare, Name: "WithBudgetShare", Params: map[string]any{"share": share}},
+		annotate:   func(spec *handlerSpec) { spec.budgetShare = share },
+	}
+}
+
+// WithMinBudget skips the handler, unless it is on the critical path, when
+// less than min is left of the budget.
+func WithMinBudget[S Store](min time.Duration) Option[S] {
+	return Option[S]{
+		descriptor: OptionDescriptor{Kind: OptionMinBudget, Name: "WithMinBudget", Params: map[string]any{"min": min}},
+		annotate:   func(spec *handlerSpec) { spec.minBudget = min },
+	}
+}
+
+func (r *Runner[S]) allocateBudget(ctx context.Context, s S, spec *handlerSpec) (context.Context, context.CancelFunc, error) {
+	if r.budget <= 0 || (spec.budgetShare <= 0 && spec.minBudget <= 0) {
+		return ctx, func() {}, nil
+	}
+
+	for _, dep := range spec.deps {
+		_, _ = s.Read(ctx, dep)
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return ctx, func() {}, nil
+	}
+	remaining := time.Until(deadline)
+
+	if remaining < spec.minBudget && !spec.critical {
+		return ctx, func() {}, errors.Join(ErrSkip, fmt.Errorf("%w: %s left, %s required", ErrBudgetExhausted, max(remaining, 0), spec.minBudget))
+	}
+
+	if spec.budgetShare <= 0 {
+		return ctx, func() {}, nil
+	}
+
+	allocated := max(time.Duration(float64(remaining)*spec.budgetShare), spec.minBudget)
+	if allocated >= remaining {
+		return ctx, func() {}, nil
+	}
+
+	budgetCtx, cancelFn := context.WithTimeout(ctx, allocated)
+	return withDeadlineSource(ctx, budgetCtx, DeadlineSourceBudget), cancelFn, nil
+}
@@ -0,0 +1,112 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Runner_Run_WithBudget_MinBudget(t *testing.T) {
+	t.Parallel()
+
+	const (
+		handlerId1 = 1
+		handlerId2 = 2
+		handlerId3 = 3
+	)
+
+	deadlineSource := func(ctx context.Context, _ Store) (any, error) {
+		return DeadlineSourceFromContext(ctx), nil
+	}
+
+	s := NewStore()
+	require.NoError(t, errors.Join(s.Register(handlerId1), s.Register(handlerId2), s.Register(handlerId3)))
+
+	r := NewRunner(WithBudget[Store](time.Millisecond * 300))
+	require.NoError(t, errors.Join(
+		r.Register(handlerId1, func(context.Context, Store) (any, error) {
+			time.Sleep(time.Millisecond * 200)
+			return nil, nil
+		}),
+		r.Register(handlerId2, deadlineSource, WithRunAfter[Store](handlerId1), WithMinBudget[Store](time.Millisecond*150)),
+		r.Register(handlerId3, deadlineSource, WithRunAfter[Store](handlerId1), WithMinBudget[Store](time.Millisecond*150), WithCriticalPath[Store]()),
+	))
+	require.NoError(t, r.Run(context.Background(), s))
+
+	data, err := s.Read(context.Background(), handlerId2)
+	require.Nil(t, data)
+	require.ErrorIs(t, err, ErrSkip)
+	require.ErrorIs(t, err, ErrBudgetExhausted)
+	require.Equal(t, OutcomeSkipped, r.HandlerStatistics()[handlerId2].Outcome)
+
+	data, err = s.Read(context.Background(), handlerId3)
+	require.NoError(t, err)
+	require.Equal(t, DeadlineSourceBudget, data)
+}
+
+func Test_Runner_Run_WithBudget_Share(t *testing.T) {
+	t.Parallel()
+
+	const (
+		handlerId1 = 1
+		handlerId2 = 2
+	)
+
+	type allocation struct {
+		remaining time.Duration
+		source    DeadlineSource
+	}
+
+	remaining := func(ctx context.Context, _ Store) (any, error) {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			return nil, errors.New("no deadline")
+		}
+		return allocation{remaining: time.Until(deadline), source: DeadlineSourceFromContext(ctx)}, nil
+	}
+
+	s := NewStore()
+	require.NoError(t, errors.Join(s.Register(handlerId1), s.Register(handlerId2)))
+
+	r := NewRunner(WithBudget[Store](time.Second))
+	require.NoError(t, errors.Join(
+		r.Register(handlerId1, remaining, WithBudgetShare[Store](0.1)),
+		r.Register(handlerId2, remaining, WithBudgetShare[Store](0.1), WithMinBudget[Store](time.Millisecond*500)),
+	))
+	require.NoError(t, r.Run(context.Background(), s))
+
+	data, err := Read[allocation](context.Background(), s, handlerId1)
+	require.NoError(t, err)
+	require.Equal(t, DeadlineSourceBudget, data.source)
+	require.LessOrEqual(t, data.remaining, time.Millisecond*100)
+	require.Greater(t, data.remaining, time.Millisecond*50)
+
+	data, err = Read[allocation](context.Background(), s, handlerId2)
+	require.NoError(t, err)
+	require.Equal(t, DeadlineSourceBudget, data.source)
+	require.LessOrEqual(t, data.remaining, time.Millisecond*500)
+	require.Greater(t, data.remaining, time.Millisecond*400)
+}
+
+func Test_Runner_Run_WithBudget_WithoutAllocation(t *testing.T) {
+	t.Parallel()
+
+	const handlerId = 1
+
+	s := NewStore()
+	require.NoError(t, s.Register(handlerId))
+
+	r := NewRunner(WithBudget[Store](time.Millisecond * 50))
+	require.NoError(t, r.Register(handlerId, func(ctx context.Context, _ Store) (any, error) {
+		<-ctx.Done()
+		return DeadlineSourceFromContext(ctx), ctx.Err()
+	}))
+	require.NoError(t, r.Run(context.Background(), s))
+
+	data, err := s.Read(context.Background(), handlerId)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, DeadlineSourceBudget, data)
+}
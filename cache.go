@@ -0,0 +1,192 @@
+package pipes
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+var ErrInvalidCapacity = errors.New("capacity must be positive")
+
+type CacheKey struct {
+	HandlerId int
+	Key       string
+}
+
+// Digest is a content address of the key, stable across processes.
+func (k CacheKey) Digest() string {
+	h := sha256.New()
+	h.Write([]byte(strconv.Itoa(k.HandlerId)))
+	h.Write([]byte{0})
+	h.Write([]byte(k.Key))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type Cache interface {
+	Get(ctx context.Context, key CacheKey) (any, bool, error)
+	Set(ctx context.Context, key CacheKey, data any) error
+}
+
+type CacheStats struct {
+	Hit bool
+	Err error
+}
+
+func WithCache[S Store](cache Cache, keyFn func(context.Context, S) (string, error)) Option[S] {
+	return Option[S]{
+		descriptor: OptionDescriptor{Kind: OptionCache, Name: "WithCache"},
+		wrap: func(next Handler[S]) Handler[S] {
+			return func(ctx context.Context, s S) (any, error) {
+				e, ok := executionFrom(ctx)
+				if !ok {
+					return next(ctx, s)
+				}
+
+				k, err := keyFn(ctx, s)
+				if err != nil {
+					return nil, err
+				}
+				key := CacheKey{HandlerId: e.handlerId, Key: k}
+
+				data, hit, getErr := cache.Get(ctx, key)
+				if getErr == nil && hit {
+					e.setCache(CacheStats{Hit: true})
+					return data, nil
+				}
+
+				data, err = next(ctx, s)
+				if err != nil {
+					e.setCache(CacheStats{Err: getErr})
+					return data, err
+				}
+
+				setErr := cache.Set(ctx, key, data)
+				e.setCache(CacheStats{Err: errors.Join(getErr, setErr)})
+				return data, nil
+			}
+		},
+	}
+}
+
+type lruEntry struct {
+	key  CacheKey
+	data any
+}
+
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	entries  map[CacheKey]*list.Element
+}
+
+func NewLRUCache(capacity int) (*LRUCache, error) {
+	if capacity <= 0 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidCapacity, capacity)
+	}
+	return &LRUCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[CacheKey]*list.Element),
+	}, nil
+}
+
+func (c *LRUCache) Get(_ context.Context, key CacheKey) (any, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*lruEntry).data, true, nil
+}
+
+func (c *LRUCache) Set(_ context.Context, key CacheKey, data any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*lruEntry).data = data
+		c.order.MoveToFront(el)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, data: data})
+	for c.order.Len() > c.capacity {
+		el := c.order.Back()
+		c.order.Remove(el)
+		delete(c.entries, el.Value.(*lruEntry).key)
+	}
+	return nil
+}
+
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// DiskCache keeps one file per key digest and serializes results through the
+// codec registry, so every cached handler needs a codec.
+type DiskCache struct {
+	dir    string
+	codecs *CodecRegistry
+}
+
+func NewDiskCache(dir string, codecs *CodecRegistry) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir: dir, codecs: codecs}, nil
+}
+
+func (c *DiskCache) Get(_ context.Context, key CacheKey) (any, bool, error) {
+	payload, err := os.ReadFile(c.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	data, err := c.codecs.Unmarshal(key.HandlerId, payload)
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+func (c *DiskCache) Set(_ context.Context, key CacheKey, data any) error {
+	payload, err := c.codecs.Marshal(key.HandlerId, data)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(c.dir, "tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err = f.Write(payload); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), c.path(key))
+}
+
+func (c *DiskCache) path(key CacheKey) string {
+	return filepath.Join(c.dir, key.Digest())
+}
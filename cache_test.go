@@ -0,0 +1,136 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewLRUCache_InvalidCapacity(t *testing.T) {
+	t.Parallel()
+
+	for _, capacity := range []int{0, -1} {
+		_, err := NewLRUCache(capacity)
+		require.ErrorIs(t, err, ErrInvalidCapacity)
+	}
+}
+
+func Test_LRUCache(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c, err := NewLRUCache(2)
+	require.NoError(t, err)
+
+	require.NoError(t, c.Set(ctx, CacheKey{HandlerId: 1, Key: "a"}, 1))
+	require.NoError(t, c.Set(ctx, CacheKey{HandlerId: 1, Key: "b"}, 2))
+
+	data, ok, err := c.Get(ctx, CacheKey{HandlerId: 1, Key: "a"})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 1, data)
+
+	require.NoError(t, c.Set(ctx, CacheKey{HandlerId: 1, Key: "c"}, 3))
+	require.Equal(t, 2, c.Len())
+
+	_, ok, err = c.Get(ctx, CacheKey{HandlerId: 1, Key: "b"})
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, ok, err = c.Get(ctx, CacheKey{HandlerId: 2, Key: "a"})
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func Test_DiskCache(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	codecs := NewCodecRegistry()
+	require.NoError(t, RegisterCodec[string](codecs, JSONCodec[string]()))
+
+	c, err := NewDiskCache(t.TempDir(), codecs)
+	require.NoError(t, err)
+
+	key := CacheKey{HandlerId: 1, Key: "a"}
+
+	_, ok, err := c.Get(ctx, key)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.NoError(t, c.Set(ctx, key, "foobar"))
+
+	data, ok, err := c.Get(ctx, key)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "foobar", data)
+
+	err = c.Set(ctx, key, 42)
+	require.ErrorIs(t, err, ErrCodecNotFound)
+}
+
+func Test_Runner_Run_WithCache(t *testing.T) {
+	t.Parallel()
+
+	const handlerId = 50
+
+	var calls atomic.Int32
+	handler := func(context.Context, Store) (any, error) {
+		calls.Add(1)
+		return "foobar", nil
+	}
+
+	keyFn := func(context.Context, Store) (string, error) {
+		return "key", nil
+	}
+
+	cache, err := NewLRUCache(8)
+	require.NoError(t, err)
+
+	for i, hit := range []bool{false, true} {
+		s := NewStore()
+		require.NoError(t, s.Register(handlerId))
+
+		r := NewRunner[Store]()
+		require.NoError(t, r.Register(handlerId, handler, WithCache(cache, keyFn)))
+		require.NoError(t, r.Run(context.Background(), s))
+
+		data, err := s.Read(context.Background(), handlerId)
+		require.NoError(t, err)
+		require.Equal(t, "foobar", data)
+
+		require.Equal(t, int32(1), calls.Load(), "run %d", i)
+		require.Equal(t, map[int]CacheStats{handlerId: {Hit: hit}}, r.CacheStatistics())
+	}
+}
+
+func Test_Runner_Run_WithCache_ErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	const handlerId = 51
+
+	handler := func(context.Context, Store) (any, error) {
+		return nil, errors.New("error in handler")
+	}
+
+	keyFn := func(context.Context, Store) (string, error) {
+		return "key", nil
+	}
+
+	cache, err := NewLRUCache(8)
+	require.NoError(t, err)
+
+	s := NewStore()
+	require.NoError(t, s.Register(handlerId))
+
+	r := NewRunner[Store]()
+	require.NoError(t, r.Register(handlerId, handler, WithCache(cache, keyFn)))
+	require.NoError(t, r.Run(context.Background(), s))
+
+	require.Equal(t, 0, cache.Len())
+	require.Equal(t, map[int]CacheStats{handlerId: {Hit: false}}, r.CacheStatistics())
+}
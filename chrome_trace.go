@@ -0,0 +1,99 @@
+package pipes
+
+import (
+	"encoding/json"
+	"io"
+	"maps"
+	"slices"
+	"time"
+)
+
+type chromeTraceEvent struct {
+	Name  string         `json:"name"`
+	Cat   string         `json:"cat,omitempty"`
+	Phase string         `json:"ph"`
+	Ts    int64          `json:"ts"`
+	Dur   int64          `json:"dur,omitempty"`
+	Pid   int            `json:"pid"`
+	Tid   int            `json:"tid"`
+	Args  map[string]any `json:"args,omitempty"`
+}
+
+type chromeTrace struct {
+	TraceEvents     []chromeTraceEvent `json:"traceEvents"`
+	DisplayTimeUnit string             `json:"displayTimeUnit"`
+}
+
+func (r *Runner[S]) WriteChromeTrace(w io.Writer) error {
+	return WriteChromeTrace(w, r.HandlerStatistics())
+}
+
+// WriteChromeTrace writes statistics in the Trace Event format understood by
+// chrome://tracing and Perfetto, one track per handler with wait and execute
+// phases nested under the handler slice.
+func WriteChromeTrace(w io.Writer, stats map[int]HandlerStats) error {
+	var origin time.Time
+	for _, s := range stats {
+		if origin.IsZero() || s.Start.Before(origin) {
+			origin = s.Start
+		}
+	}
+
+	ts := func(t time.Time) int64 {
+		return t.Sub(origin).Microseconds()
+	}
+
+	trace := chromeTrace{TraceEvents: []chromeTraceEvent{}, DisplayTimeUnit: "ms"}
+	for _, id := range slices.Sorted(maps.Keys(stats)) {
+		s := stats[id]
+		name := s.DisplayName()
+
+		trace.TraceEvents = append(trace.TraceEvents,
+			chromeTraceEvent{Name: "thread_name", Phase: "M", Pid: 1, Tid: id, Args: map[string]any{"name": name}},
+			chromeTraceEvent{Name: name, Cat: "handler", Phase: "X", Ts: ts(s.Start), Dur: s.End.Sub(s.Start).Microseconds(), Pid: 1, Tid: id, Args: chromeTraceArgs(s)},
+		)
+
+		cursor := s.Start
+		for _, wait := range mergeWaits(s.Waits) {
+			if !wait.End.After(wait.Start) {
+				continue
+			}
+			if wait.Start.After(cursor) {
+				trace.TraceEvents = append(trace.TraceEvents, chromeTraceEvent{Name: "execute", Cat: "execute", Phase: "X", Ts: ts(cursor), Dur: wait.Start.Sub(cursor).Microseconds(), Pid: 1, Tid: id})
+			}
+			trace.TraceEvents = append(trace.TraceEvents, chromeTraceEvent{Name: "wait", Cat: "wait", Phase: "X", Ts: ts(wait.Start), Dur: wait.End.Sub(wait.Start).Microseconds(), Pid: 1, Tid: id, Args: map[string]any{"on": waitedOn(s.Waits, wait)}})
+			cursor = wait.End
+		}
+		if s.End.After(cursor) {
+			trace.TraceEvents = append(trace.TraceEvents, chromeTraceEvent{Name: "execute", Cat: "execute", Phase: "X", Ts: ts(cursor), Dur: s.End.Sub(cursor).Microseconds(), Pid: 1, Tid: id})
+		}
+	}
+
+	return json.NewEncoder(w).Encode(trace)
+}
+
+func chromeTraceArgs(s HandlerStats) map[string]any {
+	args := map[string]any{
+		"handler_id": s.HandlerId,
+		"outcome":    s.Outcome.String(),
+		"attempts":   s.Attempts,
+	}
+	for k, v := range s.Labels {
+		args["label."+k] = v
+	}
+	if s.Err != nil {
+		args["err"] = s.Err.Error()
+	}
+	return args
+}
+
+func waitedOn(waits []WaitSpan, merged WaitSpan) []int {
+	var ids []int
+	for _, w := range waits {
+		if !w.Start.Before(merged.Start) && !w.End.After(merged.End) && !slices.Contains(ids, w.HandlerId) {
+			ids = append(ids, w.HandlerId)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
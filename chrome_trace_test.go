@@ -0,0 +1,67 @@
+package pipes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WriteChromeTrace(t *testing.T) {
+	t.Parallel()
+
+	origin := time.Now()
+	at := func(ms int) time.Time {
+		return origin.Add(time.Duration(ms) * time.Millisecond)
+	}
+
+	stats := map[int]HandlerStats{
+		1: {HandlerId: 1, Start: at(0), End: at(100), Outcome: OutcomeSuccess, Attempts: 1},
+		2: {
+			HandlerId: 2, Name: "process", Labels: map[string]string{"team": "core"}, Start: at(0), End: at(150), Outcome: OutcomeError, Attempts: 1, Err: errors.New("error in handler"),
+			Waits: []WaitSpan{{HandlerId: 1, Start: at(10), End: at(100)}},
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteChromeTrace(buf, stats))
+
+	var trace struct {
+		TraceEvents []chromeTraceEvent `json:"traceEvents"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &trace))
+
+	require.Equal(t, []chromeTraceEvent{
+		{Name: "thread_name", Phase: "M", Pid: 1, Tid: 1, Args: map[string]any{"name": "handler 1"}},
+		{Name: "handler 1", Cat: "handler", Phase: "X", Ts: 0, Dur: 100000, Pid: 1, Tid: 1, Args: map[string]any{"handler_id": float64(1), "outcome": "success", "attempts": float64(1)}},
+		{Name: "execute", Cat: "execute", Phase: "X", Ts: 0, Dur: 100000, Pid: 1, Tid: 1},
+		{Name: "thread_name", Phase: "M", Pid: 1, Tid: 2, Args: map[string]any{"name": "process"}},
+		{Name: "process", Cat: "handler", Phase: "X", Ts: 0, Dur: 150000, Pid: 1, Tid: 2, Args: map[string]any{"handler_id": float64(2), "label.team": "core", "outcome": "error", "attempts": float64(1), "err": "error in handler"}},
+		{Name: "execute", Cat: "execute", Phase: "X", Ts: 0, Dur: 10000, Pid: 1, Tid: 2},
+		{Name: "wait", Cat: "wait", Phase: "X", Ts: 10000, Dur: 90000, Pid: 1, Tid: 2, Args: map[string]any{"on": []any{float64(1)}}},
+		{Name: "execute", Cat: "execute", Phase: "X", Ts: 100000, Dur: 50000, Pid: 1, Tid: 2},
+	}, trace.TraceEvents)
+}
+
+func Test_Runner_WriteChromeTrace(t *testing.T) {
+	t.Parallel()
+
+	const handlerId = 1
+
+	s := NewStore()
+	require.NoError(t, s.Register(handlerId))
+
+	r := NewRunner[Store]()
+	require.NoError(t, r.Register(handlerId, func(context.Context, Store) (any, error) {
+		return nil, nil
+	}))
+	require.NoError(t, r.Run(context.Background(), s))
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, r.WriteChromeTrace(buf))
+	require.Contains(t, buf.String(), `"name":"handler 1"`)
+}
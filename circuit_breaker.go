@@ -0,0 +1,193 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	ErrCircuitOpen         = errors.New("circuit breaker is open")
+	ErrInvalidFailureRatio = errors.New("failure ratio must be in (0, 1]")
+)
+
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+type CircuitBreakerConfig struct {
+	// FailureRatio opens the breaker once failures/requests reaches it.
+	FailureRatio float64
+	// MinRequests is how many requests are needed before the ratio is checked.
+	MinRequests int
+	// Window resets the counters of the closed breaker, zero keeps them until
+	// the breaker opens.
+	Window time.Duration
+	// CoolDown is how long the breaker stays open before letting probes through.
+	CoolDown time.Duration
+	// HalfOpenRequests is how many probes may run at once, one by default.
+	HalfOpenRequests int
+}
+
+// CircuitBreaker is safe for concurrent use and meant to be shared by every
+// runner calling the same downstream.
+type CircuitBreaker struct {
+	cfg CircuitBreakerConfig
+
+	mu          sync.Mutex
+	state       CircuitState
+	requests    int
+	failures    int
+	windowStart time.Time
+	openedAt    time.Time
+	probes      int
+}
+
+func NewCircuitBreaker(cfg CircuitBreakerConfig) (*CircuitBreaker, error) {
+	if cfg.FailureRatio <= 0 || cfg.FailureRatio > 1 {
+		return nil, fmt.Errorf("%w: %g", ErrInvalidFailureRatio, cfg.FailureRatio)
+	}
+	if cfg.HalfOpenRequests <= 0 {
+		cfg.HalfOpenRequests = 1
+	}
+	if cfg.MinRequests <= 0 {
+		cfg.MinRequests = 1
+	}
+	return &CircuitBreaker{cfg: cfg, windowStart: time.Now()}, nil
+}
+
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance(time.Now())
+	return b.state
+}
+
+// Allow reserves a request, done must be called with its outcome.
+func (b *CircuitBreaker) Allow() (done func(failure bool), err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance(time.Now())
+
+	switch b.state {
+	case CircuitOpen:
+		return nil, ErrCircuitOpen
+	case CircuitHalfOpen:
+		if b.probes >= b.cfg.HalfOpenRequests {
+			return nil, ErrCircuitOpen
+		}
+		b.probes++
+		return b.doneProbe, nil
+	default:
+		return b.done, nil
+	}
+}
+
+func (b *CircuitBreaker) advance(now time.Time) {
+	switch b.state {
+	case CircuitOpen:
+		if now.Sub(b.openedAt) >= b.cfg.CoolDown {
+			b.state, b.probes = CircuitHalfOpen, 0
+		}
+	case CircuitClosed:
+		if b.cfg.Window > 0 && now.Sub(b.windowStart) >= b.cfg.Window {
+			b.requests, b.failures, b.windowStart = 0, 0, now
+		}
+	}
+}
+
+func (b *CircuitBreaker) done(failure bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != CircuitClosed {
+		return
+	}
+
+	b.requests++
+	if failure {
+		b.failures++
+	}
+	if b.failures > 0 && b.requests >= b.cfg.MinRequests && float64(b.failures)/float64(b.requests) >= b.cfg.FailureRatio {
+		b.open(time.Now())
+	}
+}
+
+func (b *CircuitBreaker) doneProbe(failure bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probes--
+	if b.state != CircuitHalfOpen {
+		return
+	}
+
+	if failure {
+		b.open(time.Now())
+		return
+	}
+	b.state, b.requests, b.failures, b.windowStart = CircuitClosed, 0, 0, time.Now()
+}
+
+func (b *CircuitBreaker) open(now time.Time) {
+	b.state, b.openedAt = CircuitOpen, now
+	b.requests, b.failures = 0, 0
+}
+
+// WithCircuitBreaker fails fast with ErrCircuitOpen while the breaker is open.
+// Errors other than skips, partial results and cancellations of the run count
+// as failures.
+func WithCircuitBreaker[S Store](b *CircuitBreaker) Option[S] {
+	return Option[S]{
+		descriptor: OptionDescriptor{Kind: OptionCircuitBreaker, Name: "WithCircuitBreaker"},
+		wrap: func(next Handler[S]) Handler[S] {
+			return func(ctx context.Context, s S) (data any, err error) {
+				done, err := b.Allow()
+				if err != nil {
+					return nil, err
+				}
+
+				defer func() {
+					if recovered := recover(); recovered != nil {
+						done(true)
+						panic(recovered)
+					}
+					done(isBreakerFailure(err))
+				}()
+
+				return next(ctx, s)
+			}
+		},
+	}
+}
+
+func isBreakerFailure(err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, ErrSkip), errors.Is(err, ErrPartialResult), errors.Is(err, context.Canceled):
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,133 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	b, err := NewCircuitBreaker(CircuitBreakerConfig{
+		FailureRatio: 0.5,
+		MinRequests:  4,
+		CoolDown:     time.Millisecond * 50,
+	})
+	require.NoError(t, err)
+	require.Equal(t, CircuitClosed, b.State())
+
+	for _, failure := range []bool{false, true, false} {
+		done, err := b.Allow()
+		require.NoError(t, err)
+		done(failure)
+	}
+	require.Equal(t, CircuitClosed, b.State())
+
+	done, err := b.Allow()
+	require.NoError(t, err)
+	done(true)
+	require.Equal(t, CircuitOpen, b.State())
+
+	_, err = b.Allow()
+	require.ErrorIs(t, err, ErrCircuitOpen)
+
+	time.Sleep(time.Millisecond * 60)
+	require.Equal(t, CircuitHalfOpen, b.State())
+
+	probe, err := b.Allow()
+	require.NoError(t, err)
+	_, err = b.Allow()
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	probe(true)
+	require.Equal(t, CircuitOpen, b.State())
+
+	time.Sleep(time.Millisecond * 60)
+	probe, err = b.Allow()
+	require.NoError(t, err)
+	probe(false)
+	require.Equal(t, CircuitClosed, b.State())
+}
+
+func Test_CircuitBreaker_Window(t *testing.T) {
+	t.Parallel()
+
+	b, err := NewCircuitBreaker(CircuitBreakerConfig{
+		FailureRatio: 1,
+		MinRequests:  2,
+		Window:       time.Millisecond * 50,
+		CoolDown:     time.Second,
+	})
+	require.NoError(t, err)
+
+	done, err := b.Allow()
+	require.NoError(t, err)
+	done(true)
+
+	time.Sleep(time.Millisecond * 60)
+
+	done, err = b.Allow()
+	require.NoError(t, err)
+	done(true)
+	require.Equal(t, CircuitClosed, b.State())
+}
+
+func Test_NewCircuitBreaker_FailureRatio(t *testing.T) {
+	t.Parallel()
+
+	for _, ratio := range []float64{0, -0.5, 1.5} {
+		_, err := NewCircuitBreaker(CircuitBreakerConfig{FailureRatio: ratio})
+		require.ErrorIs(t, err, ErrInvalidFailureRatio)
+	}
+
+	b, err := NewCircuitBreaker(CircuitBreakerConfig{FailureRatio: 0.01, CoolDown: time.Minute})
+	require.NoError(t, err)
+	for range 3 {
+		done, err := b.Allow()
+		require.NoError(t, err)
+		done(false)
+	}
+	require.Equal(t, CircuitClosed, b.State())
+}
+
+func Test_Runner_Run_WithCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	const handlerId = 1
+
+	b, err := NewCircuitBreaker(CircuitBreakerConfig{
+		FailureRatio: 1,
+		MinRequests:  2,
+		CoolDown:     time.Minute,
+	})
+	require.NoError(t, err)
+
+	var calls atomic.Int32
+	handler := func(context.Context, Store) (any, error) {
+		calls.Add(1)
+		return nil, errors.New("downstream is down")
+	}
+
+	for i := range 3 {
+		s := NewStore()
+		require.NoError(t, s.Register(handlerId))
+
+		r := NewRunner[Store]()
+		require.NoError(t, r.Register(handlerId, handler, WithCircuitBreaker[Store](b)))
+		require.NoError(t, r.Run(context.Background(), s))
+
+		_, err := s.Read(context.Background(), handlerId)
+		if i < 2 {
+			require.ErrorContains(t, err, "downstream is down")
+		} else {
+			require.ErrorIs(t, err, ErrCircuitOpen)
+		}
+	}
+
+	require.Equal(t, int32(2), calls.Load())
+	require.Equal(t, CircuitOpen, b.State())
+}
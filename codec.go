@@ -0,0 +1,181 @@
+package pipes
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var (
+	ErrCodecNotFound          = errors.New("codec not found")
+	ErrCodecAlreadyRegistered = errors.New("codec already registered")
+	ErrMalformedPayload       = errors.New("malformed payload")
+)
+
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte) (any, error)
+}
+
+type jsonCodec[T any] struct{}
+
+func JSONCodec[T any]() Codec {
+	return jsonCodec[T]{}
+}
+
+func (jsonCodec[T]) Marshal(v any) ([]byte, error) {
+	typed, ok := v.(T)
+	if !ok {
+		return nil, fmt.Errorf("invalid type %T for json codec of %T", v, *new(T))
+	}
+	return json.Marshal(typed)
+}
+
+func (jsonCodec[T]) Unmarshal(data []byte) (any, error) {
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+type gobCodec[T any] struct{}
+
+func GobCodec[T any]() Codec {
+	return gobCodec[T]{}
+}
+
+func (gobCodec[T]) Marshal(v any) ([]byte, error) {
+	typed, ok := v.(T)
+	if !ok {
+		return nil, fmt.Errorf("invalid type %T for gob codec of %T", v, *new(T))
+	}
+	buf := bytes.Buffer{}
+	if err := gob.NewEncoder(&buf).Encode(typed); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec[T]) Unmarshal(data []byte) (any, error) {
+	var v T
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// CodecRegistry resolves a codec by handler id first and by the dynamic type
+// of the result second. Payloads produced by a type codec are prefixed with
+// the type name qualified by its package path, so Unmarshal can find the
+// codec without knowing the type.
+type CodecRegistry struct {
+	mu        sync.RWMutex
+	byHandler map[int]Codec
+	byType    map[reflect.Type]Codec
+	byName    map[string]Codec
+}
+
+func NewCodecRegistry() *CodecRegistry {
+	return &CodecRegistry{
+		byHandler: make(map[int]Codec),
+		byType:    make(map[reflect.Type]Codec),
+		byName:    make(map[string]Codec),
+	}
+}
+
+func (r *CodecRegistry) RegisterHandler(handlerId int, c Codec) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byHandler[handlerId]; ok {
+		return ErrCodecAlreadyRegistered
+	}
+	r.byHandler[handlerId] = c
+	return nil
+}
+
+func RegisterCodec[T any](r *CodecRegistry, c Codec) error {
+	t := reflect.TypeFor[T]()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byType[t]; ok {
+		return ErrCodecAlreadyRegistered
+	}
+	name := typeName(t)
+	if _, ok := r.byName[name]; ok {
+		return fmt.Errorf("%w: another type is named %s", ErrCodecAlreadyRegistered, name)
+	}
+	r.byType[t] = c
+	r.byName[name] = c
+	return nil
+}
+
+// typeName tells apart named types of different packages, which t.String
+// prints the same when the last elements of their package paths match.
+func typeName(t reflect.Type) string {
+	if t.Name() != "" && t.PkgPath() != "" {
+		return t.PkgPath() + "." + t.Name()
+	}
+	return t.String()
+}
+
+func (r *CodecRegistry) Marshal(handlerId int, v any) ([]byte, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if c, ok := r.byHandler[handlerId]; ok {
+		return c.Marshal(v)
+	}
+
+	if v == nil {
+		return []byte{0}, nil
+	}
+
+	t := reflect.TypeOf(v)
+	c, ok := r.byType[t]
+	if !ok {
+		return nil, fmt.Errorf("%w: handler %d, type %s", ErrCodecNotFound, handlerId, t)
+	}
+
+	data, err := c.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	name := typeName(t)
+	payload := make([]byte, 0, len(name)+1+len(data))
+	payload = append(payload, name...)
+	payload = append(payload, 0)
+	return append(payload, data...), nil
+}
+
+func (r *CodecRegistry) Unmarshal(handlerId int, data []byte) (any, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if c, ok := r.byHandler[handlerId]; ok {
+		return c.Unmarshal(data)
+	}
+
+	i := bytes.IndexByte(data, 0)
+	if i < 0 {
+		return nil, fmt.Errorf("%w: handler %d, no type name", ErrMalformedPayload, handlerId)
+	}
+	if i == 0 {
+		return nil, nil
+	}
+
+	name := string(data[:i])
+	c, ok := r.byName[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: handler %d, type %s", ErrCodecNotFound, handlerId, name)
+	}
+	return c.Unmarshal(data[i+1:])
+}
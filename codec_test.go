@@ -0,0 +1,105 @@
+package pipes
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Codec(t *testing.T) {
+	t.Parallel()
+
+	type payload struct {
+		Name  string
+		Count int
+	}
+
+	tcs := []struct {
+		name  string
+		codec Codec
+	}{
+		{"json", JSONCodec[payload]()},
+		{"gob", GobCodec[payload]()},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := tc.codec.Marshal(payload{Name: "foobar", Count: 42})
+			require.NoError(t, err)
+
+			v, err := tc.codec.Unmarshal(data)
+			require.NoError(t, err)
+			require.Equal(t, payload{Name: "foobar", Count: 42}, v)
+
+			_, err = tc.codec.Marshal("foobar")
+			require.ErrorContains(t, err, "invalid type")
+		})
+	}
+}
+
+func Test_CodecRegistry(t *testing.T) {
+	t.Parallel()
+
+	const (
+		handlerId1 = 1
+		handlerId2 = 2
+	)
+
+	r := NewCodecRegistry()
+	require.NoError(t, r.RegisterHandler(handlerId1, GobCodec[[]int]()))
+	require.ErrorIs(t, r.RegisterHandler(handlerId1, GobCodec[[]int]()), ErrCodecAlreadyRegistered)
+	require.NoError(t, RegisterCodec[string](r, JSONCodec[string]()))
+	require.ErrorIs(t, RegisterCodec[string](r, JSONCodec[string]()), ErrCodecAlreadyRegistered)
+
+	data, err := r.Marshal(handlerId1, []int{1, 2, 3})
+	require.NoError(t, err)
+	v, err := r.Unmarshal(handlerId1, data)
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2, 3}, v)
+
+	data, err = r.Marshal(handlerId2, "foobar")
+	require.NoError(t, err)
+	v, err = r.Unmarshal(handlerId2, data)
+	require.NoError(t, err)
+	require.Equal(t, "foobar", v)
+
+	data, err = r.Marshal(handlerId2, nil)
+	require.NoError(t, err)
+	v, err = r.Unmarshal(handlerId2, data)
+	require.NoError(t, err)
+	require.Nil(t, v)
+
+	_, err = r.Marshal(handlerId2, 42)
+	require.ErrorIs(t, err, ErrCodecNotFound)
+	require.ErrorContains(t, err, "type int")
+
+	_, err = r.Unmarshal(handlerId2, []byte("int\x0042"))
+	require.ErrorIs(t, err, ErrCodecNotFound)
+
+	_, err = r.Unmarshal(handlerId2, []byte("foobar"))
+	require.ErrorIs(t, err, ErrMalformedPayload)
+}
+
+func Test_CodecRegistry_TypeNames(t *testing.T) {
+	t.Parallel()
+
+	type payload struct{ A int }
+
+	r := NewCodecRegistry()
+	require.NoError(t, RegisterCodec[payload](r, JSONCodec[payload]()))
+	{
+		type payload struct{ B string }
+		require.ErrorIs(t, RegisterCodec[payload](r, JSONCodec[payload]()), ErrCodecAlreadyRegistered)
+	}
+
+	data, err := r.Marshal(0, payload{A: 1})
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(data, []byte("github.com/foobarbazmeow/pipes.payload\x00")))
+
+	v, err := r.Unmarshal(0, data)
+	require.NoError(t, err)
+	require.Equal(t, payload{A: 1}, v)
+}
@@ -0,0 +1,68 @@
+package pipes
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// WithCompensation registers h to undo the effects of the handler. When the run
+// fails on the critical path, compensations of the handlers which succeeded run
+// one by one in reverse dependency order, see Runner.CompensationErrors.
+func WithCompensation[S Store](h Handler[S]) Option[S] {
+	return Option[S]{
+		descriptor:   OptionDescriptor{Kind: OptionCompensation, Name: "WithCompensation"},
+		compensation: h,
+	}
+}
+
+// CompensationErrors returns the errors of compensations which failed, they are
+// not part of the error returned by Run.
+func (r *Runner[S]) CompensationErrors() map[int]error {
+	r.statisticsMu.Lock()
+	defer r.statisticsMu.Unlock()
+	return maps.Clone(r.compensationErrs)
+}
+
+func (r *Runner[S]) compensate(ctx context.Context, s S) {
+	stats := r.HandlerStatistics()
+
+	order, err := topologicalOrder(stats, r.Dependencies())
+	if err != nil {
+		order = slices.SortedFunc(maps.Keys(stats), func(a, b int) int {
+			return stats[a].End.Compare(stats[b].End)
+		})
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	for _, id := range slices.Backward(order) {
+		h, ok := r.compensations[id]
+		if !ok || !compensable(stats[id].Outcome) {
+			continue
+		}
+
+		if err := runCompensation(ctx, s, id, r.specs[id].name, h); err != nil {
+			if r.logger != nil {
+				r.logger.ErrorContext(ctx, "compensation failed", "run_id", r.runId, "handler_id", id, "err", err)
+			}
+			r.statisticsMu.Lock()
+			r.compensationErrs[id] = err
+			r.statisticsMu.Unlock()
+		}
+	}
+}
+
+func runCompensation[S Store](ctx context.Context, s S, id int, name string, h Handler[S]) (err error) {
+	defer func() {
+		if recErr := recover(); recErr != nil {
+			err = fmt.Errorf("panic recover in compensation of %s: %v", describeHandler(id, name), recErr)
+		}
+	}()
+	_, err = h(ctx, s)
+	return err
+}
+
+func compensable(o Outcome) bool {
+	return o == OutcomeSuccess || o == OutcomePartial
+}
@@ -0,0 +1,78 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Runner_Run_WithCompensation(t *testing.T) {
+	t.Parallel()
+
+	const (
+		handlerId1 = 1
+		handlerId2 = 2
+		handlerId3 = 3
+	)
+
+	var (
+		mu          sync.Mutex
+		compensated []int
+	)
+	compensation := func(id int, err error) Handler[Store] {
+		return func(ctx context.Context, _ Store) (any, error) {
+			require.NoError(t, ctx.Err())
+			mu.Lock()
+			defer mu.Unlock()
+			compensated = append(compensated, id)
+			return nil, err
+		}
+	}
+	success := func(context.Context, Store) (any, error) {
+		return nil, nil
+	}
+
+	s := NewStore()
+	require.NoError(t, errors.Join(s.Register(handlerId1), s.Register(handlerId2), s.Register(handlerId3)))
+
+	r := NewRunner[Store]()
+	require.NoError(t, errors.Join(
+		r.Register(handlerId1, success, WithCompensation(compensation(handlerId1, errors.New("refund failed")))),
+		r.Register(handlerId2, success, WithRunAfter[Store](handlerId1), WithCompensation(compensation(handlerId2, nil))),
+		r.Register(handlerId3, func(context.Context, Store) (any, error) {
+			return nil, errors.New("error in handler")
+		}, WithRunAfter[Store](handlerId2), WithCriticalPath[Store](), WithCompensation(compensation(handlerId3, nil))),
+	))
+
+	err := r.Run(context.Background(), s)
+	require.ErrorIs(t, err, ErrCriticalPath)
+	require.NotContains(t, err.Error(), "refund failed")
+
+	require.Equal(t, []int{handlerId2, handlerId1}, compensated)
+	require.Equal(t, map[int]error{handlerId1: errors.New("refund failed")}, r.CompensationErrors())
+}
+
+func Test_Runner_Run_WithCompensation_Success(t *testing.T) {
+	t.Parallel()
+
+	const handlerId = 1
+
+	var compensated bool
+	s := NewStore()
+	require.NoError(t, s.Register(handlerId))
+
+	r := NewRunner[Store]()
+	require.NoError(t, r.Register(handlerId, func(context.Context, Store) (any, error) {
+		return nil, nil
+	}, WithCompensation(func(context.Context, Store) (any, error) {
+		compensated = true
+		return nil, nil
+	})))
+	require.NoError(t, r.Run(context.Background(), s))
+
+	require.False(t, compensated)
+	require.Empty(t, r.CompensationErrors())
+}
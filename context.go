@@ -0,0 +1,320 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+type (
+	executionKey      struct{}
+	attemptKey        struct{}
+	deadlineSourceKey struct{}
+	softDeadlineKey   struct{}
+)
+
+type DeadlineSource int
+
+const (
+	DeadlineSourceNone DeadlineSource = iota
+	// DeadlineSourceParent is a deadline of the context passed to Runner.Run.
+	DeadlineSourceParent
+	DeadlineSourceTimeout
+	DeadlineSourceBudget
+)
+
+func (s DeadlineSource) String() string {
+	switch s {
+	case DeadlineSourceParent:
+		return "parent"
+	case DeadlineSourceTimeout:
+		return "timeout"
+	case DeadlineSourceBudget:
+		return "budget"
+	default:
+		return "none"
+	}
+}
+
+type HandlerInfo struct {
+	HandlerId      int
+	Name           string
+	Labels         map[string]string
+	RunId          string
+	Attempt        int
+	DeadlineSource DeadlineSource
+}
+
+func HandlerInfoFromContext(ctx context.Context) (HandlerInfo, bool) {
+	e, ok := executionFrom(ctx)
+	if !ok {
+		return HandlerInfo{}, false
+	}
+
+	attempt, _ := AttemptFromContext(ctx)
+	return HandlerInfo{
+		HandlerId:      e.handlerId,
+		Name:           e.name,
+		Labels:         e.labels,
+		RunId:          e.runId,
+		Attempt:        attempt,
+		DeadlineSource: DeadlineSourceFromContext(ctx),
+	}, true
+}
+
+func HandlerIdFromContext(ctx context.Context) (int, bool) {
+	e, ok := executionFrom(ctx)
+	if !ok {
+		return 0, false
+	}
+	return e.handlerId, true
+}
+
+func RunIdFromContext(ctx context.Context) (string, bool) {
+	e, ok := executionFrom(ctx)
+	if !ok {
+		return "", false
+	}
+	return e.runId, true
+}
+
+func AttemptFromContext(ctx context.Context) (int, bool) {
+	if attempt, ok := ctx.Value(attemptKey{}).(int); ok {
+		return attempt, true
+	}
+	if _, ok := executionFrom(ctx); ok {
+		return 1, true
+	}
+	return 0, false
+}
+
+func withAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+// SoftDeadline is closed when the soft timeout of the handler expires, the
+// handler is expected to return what it has by then. It is nil without
+// WithSoftTimeout, so it never fires in a select.
+func SoftDeadline(ctx context.Context) <-chan struct{} {
+	soft, _ := ctx.Value(softDeadlineKey{}).(chan struct{})
+	return soft
+}
+
+func withSoftDeadline(ctx context.Context, soft chan struct{}) context.Context {
+	return context.WithValue(ctx, softDeadlineKey{}, soft)
+}
+
+type deadlineSource struct {
+	source   DeadlineSource
+	deadline time.Time
+}
+
+// DeadlineSourceFromContext tells which option set the effective deadline of ctx.
+func DeadlineSourceFromContext(ctx context.Context) DeadlineSource {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return DeadlineSourceNone
+	}
+	if ds, ok := ctx.Value(deadlineSourceKey{}).(deadlineSource); ok && ds.deadline.Equal(deadline) {
+		return ds.source
+	}
+	return DeadlineSourceParent
+}
+
+// withDeadlineSource marks the deadline of ctx as set by source, unless ctx
+// inherited it from parent.
+func withDeadlineSource(parent, ctx context.Context, source DeadlineSource) context.Context {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return ctx
+	}
+	if parentDeadline, ok := parent.Deadline(); ok && parentDeadline.Equal(deadline) {
+		return ctx
+	}
+	return context.WithValue(ctx, deadlineSourceKey{}, deadlineSource{source: source, deadline: deadline})
+}
+
+// execution is the per-handler record of a single run. The runner puts it into
+// the handler context, so options and the store can report what happened
+// without changing the Handler signature.
+type execution struct {
+	handlerId int
+	name      string
+	labels    map[string]string
+	runId     string
+	observer  RunnerObserver
+	// names of all handlers of the run, to refer to upstream handlers in errors
+	names map[int]string
+
+	mu       sync.Mutex
+	start    time.Time
+	end      time.Time
+	waits    []WaitSpan
+	outcome  Outcome
+	attempts int
+	winner   int
+	throttle time.Duration
+	shared   bool
+	err      error
+	// deferred are errors of options which could not return them, such as a
+	// teardown after a panic
+	deferred []error
+	cache    CacheStats
+	hasCache bool
+}
+
+func withExecution(ctx context.Context, e *execution) context.Context {
+	return context.WithValue(ctx, executionKey{}, e)
+}
+
+func executionFrom(ctx context.Context) (*execution, bool) {
+	e, ok := ctx.Value(executionKey{}).(*execution)
+	return e, ok
+}
+
+// handlerRef refers to a handler by id and, inside a run, by name.
+func handlerRef(ctx context.Context, id int) string {
+	if e, ok := executionFrom(ctx); ok {
+		return describeHandler(id, e.names[id])
+	}
+	return describeHandler(id, "")
+}
+
+func (e *execution) begin() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.start, e.attempts = time.Now(), 1
+}
+
+func (e *execution) setAttempts(attempts int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.attempts = max(e.attempts, attempts)
+}
+
+func (e *execution) setWinner(attempt int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.winner = attempt
+}
+
+func (e *execution) finish(outcome Outcome, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.end, e.outcome, e.err = time.Now(), outcome, err
+}
+
+func (e *execution) event() HandlerEvent {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return HandlerEvent{
+		RunId:     e.runId,
+		HandlerId: e.handlerId,
+		Name:      e.name,
+		Labels:    e.labels,
+		Attempt:   e.attempts,
+		Outcome:   e.outcome,
+	}
+}
+
+func (e *execution) duration() time.Duration {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.end.Sub(e.start)
+}
+
+func (e *execution) waiting(ctx context.Context, handlerId int) {
+	if e.observer != nil {
+		e.observer.OnHandlerWaiting(ctx, e.event(), handlerId)
+	}
+}
+
+func (e *execution) addWait(handlerId int, start, end time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.waits = append(e.waits, WaitSpan{HandlerId: handlerId, Start: start, End: end})
+}
+
+func (e *execution) addThrottle(d time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.throttle += d
+}
+
+func (e *execution) setShared(shared bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.shared = shared
+}
+
+func (e *execution) addDeferredErr(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.deferred = append(e.deferred, err)
+}
+
+func (e *execution) deferredErr() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return errors.Join(e.deferred...)
+}
+
+func (e *execution) setCache(stats CacheStats) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.cache, e.hasCache = stats, true
+}
+
+func (e *execution) stats() HandlerStats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var waiting time.Duration
+	for _, w := range mergeWaits(e.waits) {
+		waiting += w.End.Sub(w.Start)
+	}
+
+	return HandlerStats{
+		HandlerId: e.handlerId,
+		Name:      e.name,
+		Labels:    e.labels,
+		Start:     e.start,
+		End:       e.end,
+		Waiting:   waiting,
+		Throttled: e.throttle,
+		Executing: e.end.Sub(e.start) - waiting - e.throttle,
+		Waits:     slices.Clone(e.waits),
+		Outcome:   e.outcome,
+		Attempts:  e.attempts,
+		Winner:    e.winner,
+		CacheHit:  e.cache.Hit,
+		Shared:    e.shared,
+		Err:       e.err,
+	}
+}
+
+// mergeWaits collapses overlapping waits, so reads issued from several
+// goroutines of one handler are not counted twice.
+func mergeWaits(waits []WaitSpan) []WaitSpan {
+	if len(waits) == 0 {
+		return nil
+	}
+
+	sorted := slices.Clone(waits)
+	slices.SortFunc(sorted, func(a, b WaitSpan) int { return a.Start.Compare(b.Start) })
+
+	merged := []WaitSpan{sorted[0]}
+	for _, w := range sorted[1:] {
+		last := &merged[len(merged)-1]
+		if w.Start.After(last.End) {
+			merged = append(merged, w)
+			continue
+		}
+		if w.End.After(last.End) {
+			last.End = w.End
+		}
+	}
+	return merged
+}
@@ -0,0 +1,122 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_HandlerInfoFromContext(t *testing.T) {
+	t.Parallel()
+
+	const (
+		handlerId1 = 1
+		handlerId2 = 2
+	)
+
+	type found struct {
+		info HandlerInfo
+		ok   bool
+	}
+
+	infos := make(chan found, 2)
+	handler := func(ctx context.Context, _ Store) (any, error) {
+		info, ok := HandlerInfoFromContext(ctx)
+		infos <- found{info: info, ok: ok}
+		return nil, nil
+	}
+
+	s := NewStore()
+	require.NoError(t, errors.Join(s.Register(handlerId1), s.Register(handlerId2)))
+
+	r := NewRunner[Store]()
+	require.NoError(t, errors.Join(
+		r.Register(handlerId1, handler, WithName[Store]("fetch")),
+		r.Register(handlerId2, handler, WithTimeout[Store](time.Second)),
+	))
+	require.NoError(t, r.Run(context.Background(), s))
+	close(infos)
+
+	result := map[int]HandlerInfo{}
+	for f := range infos {
+		require.True(t, f.ok)
+		result[f.info.HandlerId] = f.info
+	}
+
+	require.Equal(t, HandlerInfo{
+		HandlerId:      handlerId1,
+		Name:           "fetch",
+		RunId:          r.RunId(),
+		Attempt:        1,
+		DeadlineSource: DeadlineSourceNone,
+	}, result[handlerId1])
+	require.Equal(t, HandlerInfo{
+		HandlerId:      handlerId2,
+		RunId:          r.RunId(),
+		Attempt:        1,
+		DeadlineSource: DeadlineSourceTimeout,
+	}, result[handlerId2])
+}
+
+func Test_HandlerInfoFromContext_OutsideRun(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	_, ok := HandlerInfoFromContext(ctx)
+	require.False(t, ok)
+	_, ok = HandlerIdFromContext(ctx)
+	require.False(t, ok)
+	_, ok = RunIdFromContext(ctx)
+	require.False(t, ok)
+	_, ok = AttemptFromContext(ctx)
+	require.False(t, ok)
+}
+
+func Test_DeadlineSourceFromContext(t *testing.T) {
+	t.Parallel()
+
+	sources := make(chan DeadlineSource, 1)
+	handler := func(ctx context.Context, _ Store) (any, error) {
+		sources <- DeadlineSourceFromContext(ctx)
+		return nil, nil
+	}
+
+	tcs := []struct {
+		name    string
+		parent  time.Duration
+		timeout time.Duration
+		source  DeadlineSource
+	}{
+		{"no deadline", 0, 0, DeadlineSourceNone},
+		{"parent deadline", time.Second, 0, DeadlineSourceParent},
+		{"handler timeout", 0, time.Second, DeadlineSourceTimeout},
+		{"handler timeout is shorter", time.Minute, time.Second, DeadlineSourceTimeout},
+		{"parent deadline is shorter", time.Second, time.Minute, DeadlineSourceParent},
+	}
+
+	for _, tc := range tcs {
+		ctx := context.Background()
+		if tc.parent > 0 {
+			var cancelFn context.CancelFunc
+			ctx, cancelFn = context.WithTimeout(ctx, tc.parent)
+			defer cancelFn()
+		}
+
+		var opts []Option[Store]
+		if tc.timeout > 0 {
+			opts = append(opts, WithTimeout[Store](tc.timeout))
+		}
+
+		s := NewStore()
+		require.NoError(t, s.Register(1))
+
+		r := NewRunner[Store]()
+		require.NoError(t, r.Register(1, handler, opts...))
+		require.NoError(t, r.Run(ctx, s))
+		require.Equal(t, tc.source, <-sources, tc.name)
+	}
+}
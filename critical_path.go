@@ -0,0 +1,157 @@
+package pipes
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+var ErrDependencyCycle = errors.New("dependency cycle")
+
+type CriticalPathStep struct {
+	HandlerId int
+	// Duration is the execution time of the handler, without waiting on upstream.
+	Duration time.Duration
+	// Finish is the earliest time the handler could finish, relative to the run start.
+	Finish time.Duration
+}
+
+type CriticalPath struct {
+	Steps []CriticalPathStep
+	Total time.Duration
+	// Slack is how much a handler could be delayed without delaying the run.
+	Slack map[int]time.Duration
+}
+
+// Dependencies returns declared dependencies merged with the ones observed
+// through Store.Read during the run.
+func (r *Runner[S]) Dependencies() map[int][]int {
+	deps := make(map[int][]int, len(r.specs))
+	for id, spec := range r.specs {
+		deps[id] = slices.Clone(spec.deps)
+	}
+
+	for id, stats := range r.HandlerStatistics() {
+		for _, w := range stats.Waits {
+			if !slices.Contains(deps[id], w.HandlerId) {
+				deps[id] = append(deps[id], w.HandlerId)
+			}
+		}
+	}
+
+	for id := range deps {
+		slices.Sort(deps[id])
+	}
+	return deps
+}
+
+func (r *Runner[S]) CriticalPath() (CriticalPath, error) {
+	return ComputeCriticalPath(r.HandlerStatistics(), r.Dependencies())
+}
+
+func ComputeCriticalPath(stats map[int]HandlerStats, deps map[int][]int) (CriticalPath, error) {
+	order, err := topologicalOrder(stats, deps)
+	if err != nil {
+		return CriticalPath{}, err
+	}
+
+	cost := func(id int) time.Duration {
+		return max(stats[id].Executing, 0)
+	}
+
+	finish := make(map[int]time.Duration, len(order))
+	via := make(map[int]int, len(order))
+	successors := make(map[int][]int, len(order))
+
+	var total time.Duration
+	var last int
+	for i, id := range order {
+		var start time.Duration
+		for _, dep := range knownDeps(stats, deps, id) {
+			successors[dep] = append(successors[dep], id)
+			if _, ok := via[id]; !ok || finish[dep] > start {
+				start, via[id] = finish[dep], dep
+			}
+		}
+		finish[id] = start + cost(id)
+		if i == 0 || finish[id] > total {
+			total, last = finish[id], id
+		}
+	}
+
+	latest := make(map[int]time.Duration, len(order))
+	for _, id := range slices.Backward(order) {
+		latest[id] = total
+		for _, succ := range successors[id] {
+			latest[id] = min(latest[id], latest[succ]-cost(succ))
+		}
+	}
+
+	result := CriticalPath{Total: total, Slack: make(map[int]time.Duration, len(order))}
+	for _, id := range order {
+		result.Slack[id] = latest[id] - finish[id]
+	}
+
+	if len(order) > 0 {
+		for id, ok := last, true; ok; id, ok = via[id] {
+			result.Steps = append(result.Steps, CriticalPathStep{HandlerId: id, Duration: cost(id), Finish: finish[id]})
+		}
+	}
+	slices.Reverse(result.Steps)
+
+	return result, nil
+}
+
+func knownDeps(stats map[int]HandlerStats, deps map[int][]int, id int) []int {
+	var result []int
+	for _, dep := range deps[id] {
+		if _, ok := stats[dep]; ok && dep != id {
+			result = append(result, dep)
+		}
+	}
+	return result
+}
+
+func topologicalOrder(stats map[int]HandlerStats, deps map[int][]int) ([]int, error) {
+	ids := slices.Sorted(maps.Keys(stats))
+
+	indegree := make(map[int]int, len(ids))
+	successors := make(map[int][]int, len(ids))
+	for _, id := range ids {
+		for _, dep := range knownDeps(stats, deps, id) {
+			indegree[id]++
+			successors[dep] = append(successors[dep], id)
+		}
+	}
+
+	var queue, order []int
+	for _, id := range ids {
+		if indegree[id] == 0 {
+			queue = append(queue, id)
+		}
+	}
+
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		order = append(order, id)
+		for _, succ := range successors[id] {
+			if indegree[succ]--; indegree[succ] == 0 {
+				queue = append(queue, succ)
+			}
+		}
+	}
+
+	if len(order) != len(ids) {
+		var cycle []int
+		for _, id := range ids {
+			if indegree[id] > 0 {
+				cycle = append(cycle, id)
+			}
+		}
+		return nil, fmt.Errorf("%w: handlers %v", ErrDependencyCycle, cycle)
+	}
+	return order, nil
+}
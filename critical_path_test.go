@@ -0,0 +1,102 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ComputeCriticalPath(t *testing.T) {
+	t.Parallel()
+
+	ms := time.Millisecond
+	stats := map[int]HandlerStats{
+		1: {HandlerId: 1, Executing: 100 * ms},
+		2: {HandlerId: 2, Executing: 50 * ms},
+		3: {HandlerId: 3, Executing: 10 * ms},
+		4: {HandlerId: 4, Executing: 20 * ms},
+		5: {HandlerId: 5, Executing: 30 * ms},
+	}
+	deps := map[int][]int{
+		3: {1, 2},
+		4: {3},
+		// unknown handlers are ignored
+		5: {42},
+	}
+
+	path, err := ComputeCriticalPath(stats, deps)
+	require.NoError(t, err)
+	require.Equal(t, 130*ms, path.Total)
+	require.Equal(t, []CriticalPathStep{
+		{HandlerId: 1, Duration: 100 * ms, Finish: 100 * ms},
+		{HandlerId: 3, Duration: 10 * ms, Finish: 110 * ms},
+		{HandlerId: 4, Duration: 20 * ms, Finish: 130 * ms},
+	}, path.Steps)
+	require.Equal(t, map[int]time.Duration{
+		1: 0,
+		2: 50 * ms,
+		3: 0,
+		4: 0,
+		5: 100 * ms,
+	}, path.Slack)
+}
+
+func Test_ComputeCriticalPath_Cycle(t *testing.T) {
+	t.Parallel()
+
+	stats := map[int]HandlerStats{1: {HandlerId: 1}, 2: {HandlerId: 2}, 3: {HandlerId: 3}}
+	deps := map[int][]int{1: {2}, 2: {1}}
+
+	_, err := ComputeCriticalPath(stats, deps)
+	require.ErrorIs(t, err, ErrDependencyCycle)
+	require.ErrorContains(t, err, "[1 2]")
+}
+
+func Test_Runner_CriticalPath(t *testing.T) {
+	t.Parallel()
+
+	const (
+		handlerId1 = 1
+		handlerId2 = 2
+		handlerId3 = 3
+	)
+
+	handler := func(delay time.Duration) Handler[Store] {
+		return func(context.Context, Store) (any, error) {
+			time.Sleep(delay)
+			return nil, nil
+		}
+	}
+
+	s := NewStore()
+	require.NoError(t, errors.Join(s.Register(handlerId1), s.Register(handlerId2), s.Register(handlerId3)))
+
+	r := NewRunner[Store]()
+	require.NoError(t, errors.Join(
+		r.Register(handlerId1, handler(time.Millisecond*200)),
+		r.Register(handlerId2, handler(time.Millisecond*10)),
+		// handler 3 reads handler 2 without declaring it
+		r.Register(handlerId3, func(ctx context.Context, s Store) (any, error) {
+			_, _ = s.Read(ctx, handlerId2)
+			return handler(time.Millisecond*50)(ctx, s)
+		}, WithRunAfter[Store](handlerId1)),
+	))
+	require.NoError(t, r.Run(context.Background(), s))
+
+	require.Equal(t, map[int][]int{
+		handlerId1: nil,
+		handlerId2: nil,
+		handlerId3: {handlerId1, handlerId2},
+	}, r.Dependencies())
+
+	path, err := r.CriticalPath()
+	require.NoError(t, err)
+	require.Len(t, path.Steps, 2)
+	require.Equal(t, handlerId1, path.Steps[0].HandlerId)
+	require.Equal(t, handlerId3, path.Steps[1].HandlerId)
+	require.Zero(t, path.Slack[handlerId1])
+	require.Greater(t, path.Slack[handlerId2], time.Millisecond*100)
+}
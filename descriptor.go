@@ -0,0 +1,97 @@
+package pipes
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+var ErrIncompatibleOptions = errors.New("incompatible options")
+
+type OptionKind int
+
+const (
+	OptionCustom OptionKind = iota
+	OptionTimeout
+	OptionSoftTimeout
+	OptionCondition
+	OptionCriticalPath
+	OptionName
+	OptionLabels
+	OptionRunAfter
+	OptionCache
+	OptionBudgetShare
+	OptionMinBudget
+	OptionHedge
+	OptionCircuitBreaker
+	OptionRateLimit
+	OptionSingleflight
+	OptionCompensation
+	OptionSetup
+	OptionTeardown
+	OptionValidate
+	OptionTransform
+)
+
+// OptionDescriptor tells which option a handler was registered with. Options
+// made by WithMiddleware are OptionCustom.
+type OptionDescriptor struct {
+	Kind   OptionKind
+	Name   string
+	Params map[string]any
+}
+
+func (d OptionDescriptor) String() string {
+	params := make([]string, 0, len(d.Params))
+	for _, k := range slices.Sorted(maps.Keys(d.Params)) {
+		params = append(params, fmt.Sprintf("%s=%v", k, d.Params[k]))
+	}
+	return d.Name + "(" + strings.Join(params, ", ") + ")"
+}
+
+// Describe replaces the name and params opt is listed with by
+// Runner.HandlerOptions, its kind is kept.
+func Describe[S Store](opt Option[S], name string, params map[string]any) Option[S] {
+	opt.descriptor.Name, opt.descriptor.Params = name, params
+	return opt
+}
+
+// HandlerOptions lists the options of every handler in the order they were
+// passed to Register, which is from the outermost to the innermost.
+func (r *Runner[S]) HandlerOptions() map[int][]OptionDescriptor {
+	result := make(map[int][]OptionDescriptor, len(r.specs))
+	for id, spec := range r.specs {
+		result[id] = slices.Clone(spec.options)
+	}
+	return result
+}
+
+func (s *handlerSpec) check() error {
+	var errs []error
+
+	condition, runAfter := s.optionIndex(OptionCondition), s.optionIndex(OptionRunAfter)
+	if condition >= 0 && runAfter > condition {
+		errs = append(errs, fmt.Errorf("%w: WithCondition is applied outside WithRunAfter", ErrIncompatibleOptions))
+	}
+
+	teardown, setup := s.optionIndex(OptionTeardown), s.optionIndex(OptionSetup)
+	if teardown >= 0 && setup > teardown {
+		errs = append(errs, fmt.Errorf("%w: WithTeardown is applied outside WithSetup", ErrIncompatibleOptions))
+	}
+
+	if s.timeout > 0 && s.softTimeout >= s.timeout {
+		errs = append(errs, fmt.Errorf("%w: soft timeout %s is not less than timeout %s", ErrIncompatibleOptions, s.softTimeout, s.timeout))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *handlerSpec) has(kind OptionKind) bool {
+	return s.optionIndex(kind) >= 0
+}
+
+func (s *handlerSpec) optionIndex(kind OptionKind) int {
+	return slices.IndexFunc(s.options, func(d OptionDescriptor) bool { return d.Kind == kind })
+}
@@ -0,0 +1,109 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Runner_HandlerOptions(t *testing.T) {
+	t.Parallel()
+
+	const (
+		handlerId1 = 1
+		handlerId2 = 2
+	)
+
+	handler := func(context.Context, Store) (any, error) {
+		return nil, nil
+	}
+	custom := Describe(WithMiddleware(func(next Handler[Store]) Handler[Store] {
+		return next
+	}), "WithAudit", map[string]any{"topic": "payments"})
+
+	r := NewRunner[Store]()
+	require.NoError(t, errors.Join(
+		r.Register(handlerId1, handler),
+		r.Register(handlerId2, handler,
+			WithName[Store]("charge"),
+			WithRunAfter[Store](handlerId1),
+			custom,
+			Describe(WithTimeout[Store](time.Second), "WithUpstreamTimeout", nil),
+			WithCriticalPath[Store](),
+			WithMiddleware(func(next Handler[Store]) Handler[Store] {
+				return next
+			}),
+		),
+	))
+
+	options := r.HandlerOptions()
+	require.Empty(t, options[handlerId1])
+	require.Equal(t, []OptionDescriptor{
+		{Kind: OptionName, Name: "WithName", Params: map[string]any{"name": "charge"}},
+		{Kind: OptionRunAfter, Name: "WithRunAfter", Params: map[string]any{"handler_ids": []int{handlerId1}}},
+		{Kind: OptionCustom, Name: "WithAudit", Params: map[string]any{"topic": "payments"}},
+		{Kind: OptionTimeout, Name: "WithUpstreamTimeout"},
+		{Kind: OptionCriticalPath, Name: "WithCriticalPath"},
+		{Kind: OptionCustom, Name: "WithMiddleware"},
+	}, options[handlerId2])
+	require.Equal(t, "WithRunAfter(handler_ids=[1])", options[handlerId2][1].String())
+}
+
+func Test_Runner_Register_IncompatibleOptions(t *testing.T) {
+	t.Parallel()
+
+	handler := func(context.Context, Store) (any, error) {
+		return nil, nil
+	}
+
+	testCases := []struct {
+		name string
+		opts []Option[Store]
+		err  string
+	}{
+		{
+			name: "condition outside run after",
+			opts: []Option[Store]{WithCondition[Store](true), WithRunAfter[Store](2)},
+			err:  "handler 1: incompatible options: WithCondition is applied outside WithRunAfter",
+		},
+		{
+			name: "described condition outside run after",
+			opts: []Option[Store]{Describe(WithCondition[Store](true), "WithFeatureFlag", nil), WithRunAfter[Store](2)},
+			err:  "handler 1: incompatible options: WithCondition is applied outside WithRunAfter",
+		},
+		{
+			name: "teardown outside setup",
+			opts: []Option[Store]{WithTeardown(func(context.Context, Store, error) error { return nil }), WithSetup(func(ctx context.Context, _ Store) (context.Context, error) { return ctx, nil })},
+			err:  "handler 1: incompatible options: WithTeardown is applied outside WithSetup",
+		},
+		{
+			name: "soft timeout not less than timeout",
+			opts: []Option[Store]{WithName[Store]("fetch"), WithSoftTimeout[Store](time.Second), WithTimeout[Store](time.Second)},
+			err:  "handler 1 (fetch): incompatible options: soft timeout 1s is not less than timeout 1s",
+		},
+		{
+			name: "compatible",
+			opts: []Option[Store]{WithRunAfter[Store](2), WithCondition[Store](true), WithTimeout[Store](time.Second), WithSoftTimeout[Store](time.Millisecond)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := NewRunner[Store]()
+			err := r.Register(1, handler, tc.opts...)
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, ErrIncompatibleOptions)
+			require.EqualError(t, err, tc.err)
+			require.Empty(t, r.HandlerOptions())
+		})
+	}
+}
@@ -30,7 +30,7 @@ func infiniteHandler(ctx context.Context, _ pipes.Store) (any, error) {
 
 func main() {
 	store := pipes.NewStore()
-	runner := pipes.NewRunner[pipes.Store]()
+	runner := pipes.NewRunner(pipes.WithLogger[pipes.Store](slog.Default()))
 	registrator := pipes.NewRegistrator(store, runner)
 
 	err := errors.Join(
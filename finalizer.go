@@ -0,0 +1,50 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type finalizer[S Store] struct {
+	handler Handler[S]
+	timeout time.Duration
+}
+
+// RegisterFinalizer adds h to run after all handlers, whatever the outcome of
+// the run, in registration order. It gets a context which is not cancelled with
+// the run and expires after timeout, zero means no timeout. Errors of
+// finalizers are joined into the error returned by Run.
+func (r *Runner[S]) RegisterFinalizer(h Handler[S], timeout time.Duration) {
+	r.finalizers = append(r.finalizers, finalizer[S]{handler: h, timeout: timeout})
+}
+
+func (r *Runner[S]) finalize(ctx context.Context, s S) error {
+	ctx = context.WithoutCancel(ctx)
+
+	var errs []error
+	for i, f := range r.finalizers {
+		errs = append(errs, runFinalizer(ctx, s, i, f))
+	}
+	return errors.Join(errs...)
+}
+
+func runFinalizer[S Store](ctx context.Context, s S, i int, f finalizer[S]) (err error) {
+	if f.timeout > 0 {
+		timeoutCtx, cancelFn := context.WithTimeout(ctx, f.timeout)
+		defer cancelFn()
+		ctx = withDeadlineSource(ctx, timeoutCtx, DeadlineSourceTimeout)
+	}
+
+	defer func() {
+		if recErr := recover(); recErr != nil {
+			err = fmt.Errorf("panic recover in finalizer %d: %v", i, recErr)
+		}
+	}()
+
+	if _, err = f.handler(ctx, s); err != nil {
+		return fmt.Errorf("finalizer %d: %w", i, err)
+	}
+	return nil
+}
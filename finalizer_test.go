@@ -0,0 +1,73 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Runner_Run_RegisterFinalizer(t *testing.T) {
+	t.Parallel()
+
+	const handlerId = 1
+
+	s := NewStore()
+	require.NoError(t, s.Register(handlerId))
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+
+	r := NewRunner[Store]()
+	require.NoError(t, r.Register(handlerId, func(ctx context.Context, _ Store) (any, error) {
+		cancelFn()
+		return "data", ctx.Err()
+	}))
+
+	var finalized []any
+	r.RegisterFinalizer(func(ctx context.Context, s Store) (any, error) {
+		require.NoError(t, ctx.Err())
+		data, err := s.Read(ctx, handlerId)
+		require.ErrorIs(t, err, context.Canceled)
+		finalized = append(finalized, data)
+		return nil, nil
+	}, 0)
+	r.RegisterFinalizer(func(ctx context.Context, _ Store) (any, error) {
+		<-ctx.Done()
+		finalized = append(finalized, DeadlineSourceFromContext(ctx))
+		return nil, ctx.Err()
+	}, time.Millisecond*20)
+	r.RegisterFinalizer(func(context.Context, Store) (any, error) {
+		panic("panic in finalizer")
+	}, 0)
+
+	err := r.Run(ctx, s)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorContains(t, err, "finalizer 1")
+	require.ErrorContains(t, err, "panic recover in finalizer 2: panic in finalizer")
+	require.Equal(t, []any{"data", DeadlineSourceTimeout}, finalized)
+}
+
+func Test_Runner_Run_RegisterFinalizer_Success(t *testing.T) {
+	t.Parallel()
+
+	const handlerId = 1
+
+	s := NewStore()
+	require.NoError(t, s.Register(handlerId))
+
+	r := NewRunner[Store]()
+	require.NoError(t, r.Register(handlerId, func(context.Context, Store) (any, error) {
+		return nil, errors.New("error in handler")
+	}))
+
+	var finalized bool
+	r.RegisterFinalizer(func(context.Context, Store) (any, error) {
+		finalized = true
+		return nil, nil
+	}, time.Second)
+
+	require.NoError(t, r.Run(context.Background(), s))
+	require.True(t, finalized)
+}
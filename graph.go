@@ -0,0 +1,210 @@
+package pipes
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+type GraphOptions struct {
+	// Outcomes colors handlers by their outcome, it only makes sense after Run.
+	Outcomes bool
+}
+
+var outcomeColors = map[Outcome]string{
+	OutcomeSuccess:   "#b7e4c7",
+	OutcomeError:     "#f4a3a3",
+	OutcomeSkipped:   "#dddddd",
+	OutcomePanic:     "#d291bc",
+	OutcomeTimeout:   "#f9c784",
+	OutcomeCancelled: "#f6e58d",
+	OutcomePartial:   "#a8d8ea",
+}
+
+type graphNode struct {
+	id      int
+	label   string
+	deps    []int
+	outcome Outcome
+}
+
+func (r *Runner[S]) graph() []graphNode {
+	deps := r.Dependencies()
+	stats := r.HandlerStatistics()
+
+	nodes := make([]graphNode, 0, len(r.specs))
+	for _, id := range slices.Sorted(maps.Keys(r.specs)) {
+		var known []int
+		for _, dep := range deps[id] {
+			if _, ok := r.specs[dep]; ok {
+				known = append(known, dep)
+			}
+		}
+		nodes = append(nodes, graphNode{
+			id:      id,
+			label:   r.specs[id].describe(),
+			deps:    known,
+			outcome: stats[id].Outcome,
+		})
+	}
+	return nodes
+}
+
+func (s *handlerSpec) describe() string {
+	var notes []string
+	if s.timeout > 0 {
+		notes = append(notes, "timeout "+s.timeout.String())
+	}
+	if s.softTimeout > 0 {
+		notes = append(notes, "soft timeout "+s.softTimeout.String())
+	}
+	if s.hedgeMaxParallel > 1 {
+		notes = append(notes, fmt.Sprintf("hedge %s x%d", s.hedgeDelay, s.hedgeMaxParallel))
+	}
+	if s.has(OptionCircuitBreaker) {
+		notes = append(notes, "circuit breaker")
+	}
+	if s.has(OptionRateLimit) {
+		notes = append(notes, "rate limit")
+	}
+	if s.has(OptionSingleflight) {
+		notes = append(notes, "singleflight")
+	}
+	if s.has(OptionCompensation) {
+		notes = append(notes, "compensation")
+	}
+	if s.has(OptionSetup) {
+		notes = append(notes, "setup")
+	}
+	if s.has(OptionTeardown) {
+		notes = append(notes, "teardown")
+	}
+	if s.has(OptionValidate) {
+		notes = append(notes, "validate")
+	}
+	if s.has(OptionTransform) {
+		notes = append(notes, "transform")
+	}
+	if s.critical {
+		notes = append(notes, "critical")
+	}
+	if s.budgetShare > 0 {
+		notes = append(notes, fmt.Sprintf("budget share %g%%", s.budgetShare*100))
+	}
+	if s.minBudget > 0 {
+		notes = append(notes, "min budget "+s.minBudget.String())
+	}
+	if s.condition != nil {
+		if *s.condition {
+			notes = append(notes, "condition: skip")
+		} else {
+			notes = append(notes, "condition: run")
+		}
+	}
+	for _, k := range slices.Sorted(maps.Keys(s.labels)) {
+		notes = append(notes, k+"="+s.labels[k])
+	}
+
+	label := s.displayName()
+	if len(notes) > 0 {
+		label += " (" + strings.Join(notes, ", ") + ")"
+	}
+	return strings.ReplaceAll(label, `"`, "'")
+}
+
+func graphNodeId(id int) string {
+	if id < 0 {
+		return fmt.Sprintf("h_%d", -id)
+	}
+	return fmt.Sprintf("h%d", id)
+}
+
+// WriteMermaid renders the pipeline as a Mermaid state diagram.
+func (r *Runner[S]) WriteMermaid(w io.Writer, opts GraphOptions) error {
+	nodes := r.graph()
+	hasDependents := dependents(nodes)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "stateDiagram-v2")
+	fmt.Fprintln(bw, "    direction LR")
+
+	for _, n := range nodes {
+		fmt.Fprintf(bw, "    state %q as %s\n", n.label, graphNodeId(n.id))
+	}
+	for _, n := range nodes {
+		if len(n.deps) == 0 {
+			fmt.Fprintf(bw, "    [*] --> %s\n", graphNodeId(n.id))
+		}
+		for _, dep := range n.deps {
+			fmt.Fprintf(bw, "    %s --> %s\n", graphNodeId(dep), graphNodeId(n.id))
+		}
+	}
+	for _, n := range nodes {
+		if !hasDependents[n.id] {
+			fmt.Fprintf(bw, "    %s --> [*]\n", graphNodeId(n.id))
+		}
+	}
+
+	if opts.Outcomes {
+		for _, outcome := range usedOutcomes(nodes) {
+			fmt.Fprintf(bw, "    classDef %s fill:%s\n", outcome, outcomeColors[outcome])
+		}
+		for _, n := range nodes {
+			if _, ok := outcomeColors[n.outcome]; ok {
+				fmt.Fprintf(bw, "    class %s %s\n", graphNodeId(n.id), n.outcome)
+			}
+		}
+	}
+
+	return bw.Flush()
+}
+
+// WriteDOT renders the pipeline as a Graphviz digraph.
+func (r *Runner[S]) WriteDOT(w io.Writer, opts GraphOptions) error {
+	nodes := r.graph()
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph pipeline {")
+	fmt.Fprintln(bw, "    rankdir=LR;")
+	fmt.Fprintln(bw, "    node [shape=box];")
+
+	for _, n := range nodes {
+		attrs := fmt.Sprintf("label=%q", n.label)
+		if color, ok := outcomeColors[n.outcome]; ok && opts.Outcomes {
+			attrs += fmt.Sprintf(", style=filled, fillcolor=%q", color)
+		}
+		fmt.Fprintf(bw, "    %s [%s];\n", graphNodeId(n.id), attrs)
+	}
+	for _, n := range nodes {
+		for _, dep := range n.deps {
+			fmt.Fprintf(bw, "    %s -> %s;\n", graphNodeId(dep), graphNodeId(n.id))
+		}
+	}
+
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+func dependents(nodes []graphNode) map[int]bool {
+	result := make(map[int]bool)
+	for _, n := range nodes {
+		for _, dep := range n.deps {
+			result[dep] = true
+		}
+	}
+	return result
+}
+
+func usedOutcomes(nodes []graphNode) []Outcome {
+	var result []Outcome
+	for _, n := range nodes {
+		if _, ok := outcomeColors[n.outcome]; ok && !slices.Contains(result, n.outcome) {
+			result = append(result, n.outcome)
+		}
+	}
+	slices.Sort(result)
+	return result
+}
@@ -0,0 +1,90 @@
+package pipes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newGraphRunner(t *testing.T) *Runner[Store] {
+	t.Helper()
+
+	handler := func(context.Context, Store) (any, error) {
+		return nil, nil
+	}
+
+	r := NewRunner[Store]()
+	require.NoError(t, errors.Join(
+		r.Register(1, handler, WithTimeout[Store](time.Second)),
+		r.Register(2, handler, WithCondition[Store](true)),
+		r.Register(3, func(context.Context, Store) (any, error) {
+			return nil, errors.New("error in handler")
+		}, WithRunAfter[Store](1, 2), WithCriticalPath[Store](), WithLabels[Store](map[string]string{"team": "payments"})),
+	))
+	return r
+}
+
+func Test_Runner_WriteMermaid(t *testing.T) {
+	t.Parallel()
+
+	r := newGraphRunner(t)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, r.WriteMermaid(buf, GraphOptions{}))
+	require.Equal(t, `stateDiagram-v2
+    direction LR
+    state "handler 1 (timeout 1s)" as h1
+    state "handler 2 (condition: skip)" as h2
+    state "handler 3 (critical, team=payments)" as h3
+    [*] --> h1
+    [*] --> h2
+    h1 --> h3
+    h2 --> h3
+    h3 --> [*]
+`, buf.String())
+
+	s := NewStore()
+	require.NoError(t, errors.Join(s.Register(1), s.Register(2), s.Register(3)))
+	require.ErrorIs(t, r.Run(context.Background(), s), ErrCriticalPath)
+
+	buf.Reset()
+	require.NoError(t, r.WriteMermaid(buf, GraphOptions{Outcomes: true}))
+	require.Contains(t, buf.String(), `    classDef success fill:#b7e4c7
+    classDef error fill:#f4a3a3
+    classDef skipped fill:#dddddd
+    class h1 success
+    class h2 skipped
+    class h3 error
+`)
+}
+
+func Test_Runner_WriteDOT(t *testing.T) {
+	t.Parallel()
+
+	r := newGraphRunner(t)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, r.WriteDOT(buf, GraphOptions{}))
+	require.Equal(t, `digraph pipeline {
+    rankdir=LR;
+    node [shape=box];
+    h1 [label="handler 1 (timeout 1s)"];
+    h2 [label="handler 2 (condition: skip)"];
+    h3 [label="handler 3 (critical, team=payments)"];
+    h1 -> h3;
+    h2 -> h3;
+}
+`, buf.String())
+
+	s := NewStore()
+	require.NoError(t, errors.Join(s.Register(1), s.Register(2), s.Register(3)))
+	require.Error(t, r.Run(context.Background(), s))
+
+	buf.Reset()
+	require.NoError(t, r.WriteDOT(buf, GraphOptions{Outcomes: true}))
+	require.Contains(t, buf.String(), `h3 [label="handler 3 (critical, team=payments)", style=filled, fillcolor="#f4a3a3"];`)
+}
@@ -0,0 +1,86 @@
+package pipes
+
+import (
+	"context"
+	"time"
+)
+
+type hedgeResult struct {
+	attempt   int
+	data      any
+	err       error
+	recovered any
+}
+
+// WithHedge launches another attempt of the handler every delay while none of
+// the running ones has finished, up to maxParallel attempts. The first
+// successful attempt wins and the rest are cancelled, so the handler must be
+// idempotent.
+func WithHedge[S Store](delay time.Duration, maxParallel int) Option[S] {
+	return Option[S]{
+		descriptor: OptionDescriptor{Kind: OptionHedge, Name: "WithHedge", Params: map[string]any{"delay": delay, "max_parallel": maxParallel}},
+		annotate:   func(spec *handlerSpec) { spec.hedgeDelay, spec.hedgeMaxParallel = delay, maxParallel },
+		wrap: func(next Handler[S]) Handler[S] {
+			return func(ctx context.Context, s S) (any, error) {
+				ctx, cancelFn := context.WithCancel(ctx)
+				defer cancelFn()
+
+				e, _ := executionFrom(ctx)
+				results := make(chan hedgeResult, max(maxParallel, 1))
+
+				launched := 0
+				launch := func() {
+					launched++
+					attempt := launched
+					if e != nil {
+						e.setAttempts(attempt)
+					}
+					go func() {
+						result := hedgeResult{attempt: attempt}
+						defer func() {
+							result.recovered = recover()
+							results <- result
+						}()
+						result.data, result.err = next(withAttempt(ctx, attempt), s)
+					}()
+				}
+
+				launch()
+				timer := time.NewTimer(delay)
+				defer timer.Stop()
+
+				var last hedgeResult
+				for pending := 1; pending > 0; {
+					hedge := timer.C
+					if launched >= maxParallel || ctx.Err() != nil {
+						hedge = nil
+					}
+
+					select {
+					case <-hedge:
+						if ctx.Err() != nil {
+							continue
+						}
+						launch()
+						pending++
+						timer.Reset(delay)
+					case result := <-results:
+						pending--
+						if result.recovered != nil {
+							panic(result.recovered)
+						}
+						if result.err == nil {
+							if e != nil {
+								e.setWinner(result.attempt)
+							}
+							return result.data, nil
+						}
+						last = result
+					}
+				}
+
+				return last.data, last.err
+			}
+		},
+	}
+}
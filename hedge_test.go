@@ -0,0 +1,119 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Runner_Run_WithHedge(t *testing.T) {
+	t.Parallel()
+
+	const handlerId = 1
+
+	cancelled := make(chan int, 3)
+	handler := func(ctx context.Context, _ Store) (any, error) {
+		attempt, _ := AttemptFromContext(ctx)
+		delay := time.Millisecond * 10
+		if attempt == 1 {
+			delay = time.Second
+		}
+
+		select {
+		case <-ctx.Done():
+			cancelled <- attempt
+			return nil, ctx.Err()
+		case <-time.After(delay):
+			return fmt.Sprintf("attempt %d", attempt), nil
+		}
+	}
+
+	s := NewStore()
+	require.NoError(t, s.Register(handlerId))
+
+	r := NewRunner[Store]()
+	require.NoError(t, r.Register(handlerId, handler, WithHedge[Store](time.Millisecond*50, 3)))
+
+	start := time.Now()
+	require.NoError(t, r.Run(context.Background(), s))
+	require.Less(t, time.Since(start), time.Millisecond*500)
+
+	data, err := s.Read(context.Background(), handlerId)
+	require.NoError(t, err)
+	require.Equal(t, "attempt 2", data)
+	require.Equal(t, 1, <-cancelled)
+
+	stats := r.HandlerStatistics()[handlerId]
+	require.Equal(t, 2, stats.Attempts)
+	require.Equal(t, 2, stats.Winner)
+}
+
+func Test_Runner_Run_WithHedge_AllAttemptsFail(t *testing.T) {
+	t.Parallel()
+
+	const handlerId = 1
+
+	handler := func(ctx context.Context, _ Store) (any, error) {
+		attempt, _ := AttemptFromContext(ctx)
+		time.Sleep(time.Millisecond * 30)
+		return nil, fmt.Errorf("error in attempt %d", attempt)
+	}
+
+	s := NewStore()
+	require.NoError(t, s.Register(handlerId))
+
+	r := NewRunner[Store]()
+	require.NoError(t, r.Register(handlerId, handler, WithHedge[Store](time.Millisecond*10, 2)))
+	require.NoError(t, r.Run(context.Background(), s))
+
+	_, err := s.Read(context.Background(), handlerId)
+	require.EqualError(t, err, "error in attempt 2")
+
+	stats := r.HandlerStatistics()[handlerId]
+	require.Equal(t, 2, stats.Attempts)
+	require.Zero(t, stats.Winner)
+}
+
+func Test_Runner_Run_WithHedge_Panic(t *testing.T) {
+	t.Parallel()
+
+	const handlerId = 1
+
+	s := NewStore()
+	require.NoError(t, s.Register(handlerId))
+
+	r := NewRunner[Store]()
+	require.NoError(t, r.Register(handlerId, func(context.Context, Store) (any, error) {
+		panic("panic in handler")
+	}, WithHedge[Store](time.Millisecond*10, 2)))
+
+	err := r.Run(context.Background(), s)
+	require.ErrorContains(t, err, "panic recover")
+	require.Equal(t, OutcomePanic, r.HandlerStatistics()[handlerId].Outcome)
+}
+
+func Test_Runner_Run_WithHedge_Cancelled(t *testing.T) {
+	t.Parallel()
+
+	const handlerId = 1
+
+	var attempts atomic.Int32
+	s := NewStore()
+	require.NoError(t, s.Register(handlerId))
+
+	r := NewRunner[Store]()
+	require.NoError(t, r.Register(handlerId, func(context.Context, Store) (any, error) {
+		attempts.Add(1)
+		time.Sleep(time.Millisecond * 100)
+		return nil, errors.New("error in handler")
+	}, WithTimeout[Store](time.Millisecond*10), WithHedge[Store](time.Millisecond*30, 3)))
+	require.NoError(t, r.Run(context.Background(), s))
+
+	require.Equal(t, int32(1), attempts.Load())
+	require.Equal(t, 1, r.HandlerStatistics()[handlerId].Attempts)
+}
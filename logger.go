@@ -0,0 +1,96 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+)
+
+type loggerKey struct{}
+
+// WithLogger logs the lifecycle of a run and puts a handler-scoped logger
+// into the handler context, see LoggerFromContext. A nil logger is ignored.
+func WithLogger[S Store](l *slog.Logger) RunnerOption[S] {
+	return func(r *Runner[S]) {
+		if l == nil {
+			return
+		}
+		r.logger = l
+		r.observers = append(r.observers, &slogObserver{l: l})
+	}
+}
+
+// LoggerFromContext returns the logger attached by WithLogger or slog.Default.
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
+
+func withLogger(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+type slogObserver struct {
+	NopObserver
+	l *slog.Logger
+}
+
+func (o *slogObserver) OnRunStart(ctx context.Context, run RunEvent) {
+	o.l.DebugContext(ctx, "pipeline started", "run_id", run.RunId)
+}
+
+func (o *slogObserver) OnHandlerStart(ctx context.Context, e HandlerEvent) {
+	o.l.DebugContext(ctx, "handler started", handlerAttrs(e)...)
+}
+
+func (o *slogObserver) OnHandlerFinish(ctx context.Context, e HandlerEvent, _ any, err error, d time.Duration) {
+	attrs := append(handlerAttrs(e), "duration", d)
+	switch {
+	case e.Outcome == OutcomePanic:
+		// logged by OnHandlerPanic
+	case err == nil:
+		o.l.InfoContext(ctx, "handler finished", attrs...)
+	case errors.Is(err, ErrSkip):
+		o.l.InfoContext(ctx, "handler skipped", attrs...)
+	case errors.Is(err, ErrPartialResult):
+		o.l.WarnContext(ctx, "handler returned partial result", attrs...)
+	default:
+		o.l.ErrorContext(ctx, "handler failed", append(attrs, "err", err)...)
+	}
+}
+
+func (o *slogObserver) OnHandlerPanic(ctx context.Context, e HandlerEvent, recovered any) {
+	o.l.ErrorContext(ctx, "handler panicked", append(handlerAttrs(e), "panic", recovered)...)
+}
+
+func (o *slogObserver) OnCancel(ctx context.Context, run RunEvent, cause error) {
+	o.l.WarnContext(ctx, "pipeline cancelled", "run_id", run.RunId, "cause", cause)
+}
+
+func (o *slogObserver) OnRunFinish(ctx context.Context, run RunEvent, err error, d time.Duration) {
+	if err != nil {
+		o.l.ErrorContext(ctx, "pipeline failed", "run_id", run.RunId, "duration", d, "err", err)
+		return
+	}
+	o.l.InfoContext(ctx, "pipeline finished", "run_id", run.RunId, "duration", d)
+}
+
+func handlerAttrs(e HandlerEvent) []any {
+	attrs := []any{"run_id", e.RunId, "handler_id", e.HandlerId}
+	if e.Name != "" {
+		attrs = append(attrs, "handler_name", e.Name)
+	}
+	if len(e.Labels) > 0 {
+		labels := make([]any, 0, len(e.Labels))
+		for _, k := range slices.Sorted(maps.Keys(e.Labels)) {
+			labels = append(labels, slog.String(k, e.Labels[k]))
+		}
+		attrs = append(attrs, slog.Group("labels", labels...))
+	}
+	return attrs
+}
@@ -0,0 +1,119 @@
+package pipes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Runner_Run_WithLogger(t *testing.T) {
+	t.Parallel()
+
+	const (
+		handlerId1 = 1
+		handlerId2 = 2
+		handlerId3 = 3
+	)
+
+	buf := &bytes.Buffer{}
+	l := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	s := NewStore()
+	require.NoError(t, errors.Join(s.Register(handlerId1), s.Register(handlerId2), s.Register(handlerId3)))
+
+	r := NewRunner(WithLogger[Store](l))
+	require.NoError(t, errors.Join(
+		r.Register(handlerId1, func(ctx context.Context, _ Store) (any, error) {
+			LoggerFromContext(ctx).Info("inside handler")
+			return nil, nil
+		}),
+		r.Register(handlerId2, func(context.Context, Store) (any, error) {
+			return nil, errors.New("error in handler")
+		}),
+		r.Register(handlerId3, func(context.Context, Store) (any, error) {
+			return nil, nil
+		}, WithCondition[Store](true)),
+	))
+	require.NoError(t, r.Run(context.Background(), s))
+
+	records := map[string][]map[string]any{}
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(line, &record))
+		require.Equal(t, r.RunId(), record["run_id"])
+		records[record["msg"].(string)] = append(records[record["msg"].(string)], record)
+	}
+
+	require.Len(t, records["pipeline started"], 1)
+	require.Len(t, records["pipeline finished"], 1)
+	require.Len(t, records["handler started"], 3)
+	require.Len(t, records["handler finished"], 1)
+
+	require.Len(t, records["inside handler"], 1)
+	require.Equal(t, float64(handlerId1), records["inside handler"][0]["handler_id"])
+
+	require.Len(t, records["handler failed"], 1)
+	require.Equal(t, float64(handlerId2), records["handler failed"][0]["handler_id"])
+	require.Equal(t, "ERROR", records["handler failed"][0]["level"])
+	require.Equal(t, "error in handler", records["handler failed"][0]["err"])
+	require.Contains(t, records["handler failed"][0], "duration")
+
+	require.Len(t, records["handler skipped"], 1)
+	require.Equal(t, float64(handlerId3), records["handler skipped"][0]["handler_id"])
+}
+
+func Test_Runner_Run_WithLogger_Panic(t *testing.T) {
+	t.Parallel()
+
+	const handlerId = 1
+
+	buf := &bytes.Buffer{}
+	l := slog.New(slog.NewJSONHandler(buf, nil))
+
+	s := NewStore()
+	require.NoError(t, s.Register(handlerId))
+
+	r := NewRunner(WithLogger[Store](l))
+	require.NoError(t, r.Register(handlerId, func(context.Context, Store) (any, error) {
+		panic("panic in handler")
+	}))
+	require.Error(t, r.Run(context.Background(), s))
+
+	var messages []string
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(line, &record))
+		messages = append(messages, record["msg"].(string))
+	}
+	require.Equal(t, []string{"handler panicked", "pipeline failed"}, messages)
+}
+
+func Test_Runner_Run_WithLogger_Nil(t *testing.T) {
+	t.Parallel()
+
+	const handlerId = 1
+
+	s := NewStore()
+	require.NoError(t, s.Register(handlerId))
+
+	r := NewRunner(WithLogger[Store](nil))
+	require.NoError(t, r.Register(handlerId, func(ctx context.Context, _ Store) (any, error) {
+		return LoggerFromContext(ctx) == slog.Default(), nil
+	}))
+	require.NoError(t, r.Run(context.Background(), s))
+
+	data, err := s.Read(context.Background(), handlerId)
+	require.NoError(t, err)
+	require.Equal(t, true, data)
+}
+
+func Test_LoggerFromContext_Default(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, slog.Default(), LoggerFromContext(context.Background()))
+}
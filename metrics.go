@@ -0,0 +1,215 @@
+package pipes
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var DefaultMetricsBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func (h *histogram) observe(buckets []float64, v float64) {
+	if h.counts == nil {
+		h.counts = make([]uint64, len(buckets))
+	}
+	for i, le := range buckets {
+		if v <= le {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += v
+}
+
+// MetricsCollector aggregates runs of any number of runners attached with
+// WithObserver and serves them in the Prometheus text exposition format.
+type MetricsCollector struct {
+	NopObserver
+
+	buckets []float64
+
+	mu               sync.Mutex
+	handlerDurations map[string]*histogram
+	handlerOutcomes  map[[2]string]uint64
+	handlersInFlight map[string]int64
+	runDurations     *histogram
+	runs             map[string]uint64
+	runsInFlight     int64
+}
+
+func NewMetricsCollector(buckets ...float64) *MetricsCollector {
+	if len(buckets) == 0 {
+		buckets = DefaultMetricsBuckets
+	}
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+
+	return &MetricsCollector{
+		buckets:          buckets,
+		handlerDurations: make(map[string]*histogram),
+		handlerOutcomes:  make(map[[2]string]uint64),
+		handlersInFlight: make(map[string]int64),
+		runDurations:     &histogram{},
+		runs:             make(map[string]uint64),
+	}
+}
+
+func (c *MetricsCollector) OnRunStart(context.Context, RunEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.runsInFlight++
+}
+
+func (c *MetricsCollector) OnHandlerStart(_ context.Context, e HandlerEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handlersInFlight[metricsHandlerLabels(e)]++
+}
+
+func (c *MetricsCollector) OnHandlerFinish(_ context.Context, e HandlerEvent, _ any, _ error, d time.Duration) {
+	handler := metricsHandlerLabels(e)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.handlersInFlight[handler]--
+	c.handlerOutcomes[[2]string{handler, e.Outcome.String()}]++
+
+	h, ok := c.handlerDurations[handler]
+	if !ok {
+		h = &histogram{}
+		c.handlerDurations[handler] = h
+	}
+	h.observe(c.buckets, d.Seconds())
+}
+
+func (c *MetricsCollector) OnRunFinish(_ context.Context, _ RunEvent, err error, d time.Duration) {
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.runsInFlight--
+	c.runs[status]++
+	c.runDurations.observe(c.buckets, d.Seconds())
+}
+
+func (c *MetricsCollector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = c.WriteText(w)
+}
+
+func (c *MetricsCollector) WriteText(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+
+	writeHeader(bw, "pipes_handler_duration_seconds", "histogram", "Duration of handler executions.")
+	for _, handler := range slices.Sorted(maps.Keys(c.handlerDurations)) {
+		c.writeHistogram(bw, "pipes_handler_duration_seconds", handler, c.handlerDurations[handler])
+	}
+
+	writeHeader(bw, "pipes_handler_outcomes_total", "counter", "Finished handler executions by outcome.")
+	outcomes := slices.SortedFunc(maps.Keys(c.handlerOutcomes), func(a, b [2]string) int {
+		return strings.Compare(a[0]+"\x00"+a[1], b[0]+"\x00"+b[1])
+	})
+	for _, key := range outcomes {
+		fmt.Fprintf(bw, "pipes_handler_outcomes_total{%s,%s} %d\n", key[0], label("outcome", key[1]), c.handlerOutcomes[key])
+	}
+
+	writeHeader(bw, "pipes_handlers_in_flight", "gauge", "Handlers currently executing.")
+	for _, handler := range slices.Sorted(maps.Keys(c.handlersInFlight)) {
+		fmt.Fprintf(bw, "pipes_handlers_in_flight{%s} %d\n", handler, c.handlersInFlight[handler])
+	}
+
+	writeHeader(bw, "pipes_run_duration_seconds", "histogram", "Duration of pipeline runs.")
+	if c.runDurations.count > 0 {
+		c.writeHistogram(bw, "pipes_run_duration_seconds", "", c.runDurations)
+	}
+
+	writeHeader(bw, "pipes_runs_total", "counter", "Finished pipeline runs by status.")
+	for _, status := range slices.Sorted(maps.Keys(c.runs)) {
+		fmt.Fprintf(bw, "pipes_runs_total{%s} %d\n", label("status", status), c.runs[status])
+	}
+
+	writeHeader(bw, "pipes_runs_in_flight", "gauge", "Pipeline runs currently executing.")
+	fmt.Fprintf(bw, "pipes_runs_in_flight %d\n", c.runsInFlight)
+
+	return bw.Flush()
+}
+
+func (c *MetricsCollector) writeHistogram(w *bufio.Writer, name, labels string, h *histogram) {
+	sep := ""
+	if labels != "" {
+		sep = ","
+	}
+	for i, le := range c.buckets {
+		fmt.Fprintf(w, "%s_bucket{%s%s%s} %d\n", name, labels, sep, label("le", strconv.FormatFloat(le, 'g', -1, 64)), h.counts[i])
+	}
+	fmt.Fprintf(w, "%s_bucket{%s%s%s} %d\n", name, labels, sep, label("le", "+Inf"), h.count)
+	fmt.Fprintf(w, "%s_sum%s %s\n", name, braces(labels), strconv.FormatFloat(h.sum, 'g', -1, 64))
+	fmt.Fprintf(w, "%s_count%s %d\n", name, braces(labels), h.count)
+}
+
+func writeHeader(w *bufio.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func braces(labels string) string {
+	if labels == "" {
+		return ""
+	}
+	return "{" + labels + "}"
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func label(name, value string) string {
+	return name + `="` + labelEscaper.Replace(value) + `"`
+}
+
+// metricsHandlerLabels renders the labels of a handler series. Label names of
+// WithLabels are sanitized, the ones taken by the collector get a label_ prefix.
+func metricsHandlerLabels(e HandlerEvent) string {
+	labels := []string{label("handler_id", strconv.Itoa(e.HandlerId)), label("handler_name", e.Name)}
+	for _, k := range slices.Sorted(maps.Keys(e.Labels)) {
+		name := metricsLabelName(k)
+		switch name {
+		case "handler_id", "handler_name", "outcome", "le":
+			name = "label_" + name
+		}
+		labels = append(labels, label(name, e.Labels[k]))
+	}
+	return strings.Join(labels, ",")
+}
+
+func metricsLabelName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		if c != '_' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			b[i] = '_'
+		}
+	}
+	if len(b) == 0 || b[0] >= '0' && b[0] <= '9' {
+		return "_" + string(b)
+	}
+	return string(b)
+}
@@ -0,0 +1,82 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_MetricsCollector(t *testing.T) {
+	t.Parallel()
+
+	const (
+		handlerId1 = 1
+		handlerId2 = 2
+		handlerId3 = 3
+	)
+
+	c := NewMetricsCollector(1, 0.05)
+
+	for range 2 {
+		s := NewStore()
+		require.NoError(t, errors.Join(s.Register(handlerId1), s.Register(handlerId2), s.Register(handlerId3)))
+
+		r := NewRunner(WithObserver[Store](c))
+		require.NoError(t, errors.Join(
+			r.Register(handlerId1, func(context.Context, Store) (any, error) {
+				return nil, nil
+			}, WithName[Store]("3"), WithLabels[Store](map[string]string{"team": "core", "cost-center": "42", "outcome": "x"})),
+			r.Register(handlerId2, func(context.Context, Store) (any, error) {
+				panic("panic in handler")
+			}),
+			r.Register(handlerId3, func(ctx context.Context, _ Store) (any, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}, WithTimeout[Store](time.Millisecond*100)),
+		))
+		require.Error(t, r.Run(context.Background(), s))
+	}
+
+	w := httptest.NewRecorder()
+	c.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	require.Equal(t, "text/plain; version=0.0.4; charset=utf-8", w.Header().Get("Content-Type"))
+
+	body := w.Body.String()
+	for _, line := range []string{
+		"# TYPE pipes_handler_duration_seconds histogram",
+		`pipes_handler_duration_seconds_bucket{handler_id="1",handler_name="3",cost_center="42",label_outcome="x",team="core",le="0.05"} 2`,
+		`pipes_handler_duration_seconds_bucket{handler_id="3",handler_name="",le="0.05"} 0`,
+		`pipes_handler_duration_seconds_bucket{handler_id="3",handler_name="",le="1"} 2`,
+		`pipes_handler_duration_seconds_bucket{handler_id="3",handler_name="",le="+Inf"} 2`,
+		`pipes_handler_duration_seconds_count{handler_id="3",handler_name=""} 2`,
+		"# TYPE pipes_handler_outcomes_total counter",
+		`pipes_handler_outcomes_total{handler_id="1",handler_name="3",cost_center="42",label_outcome="x",team="core",outcome="success"} 2`,
+		`pipes_handler_outcomes_total{handler_id="2",handler_name="",outcome="panic"} 2`,
+		`pipes_handler_outcomes_total{handler_id="3",handler_name="",outcome="timeout"} 2`,
+		`pipes_handlers_in_flight{handler_id="1",handler_name="3",cost_center="42",label_outcome="x",team="core"} 0`,
+		`pipes_run_duration_seconds_count 2`,
+		`pipes_runs_total{status="error"} 2`,
+		"pipes_runs_in_flight 0",
+	} {
+		require.Contains(t, body, line+"\n")
+	}
+}
+
+func Test_label(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, `handler="a\\b\"c\nd"`, label("handler", "a\\b\"c\nd"))
+}
+
+func Test_metricsLabelName(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "team", metricsLabelName("team"))
+	require.Equal(t, "cost_center_2", metricsLabelName("cost-center.2"))
+	require.Equal(t, "_1st", metricsLabelName("1st"))
+	require.Equal(t, "_", metricsLabelName(""))
+}
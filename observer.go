@@ -0,0 +1,100 @@
+package pipes
+
+import (
+	"context"
+	"time"
+)
+
+type RunEvent struct {
+	RunId string
+	Start time.Time
+}
+
+type HandlerEvent struct {
+	RunId     string
+	HandlerId int
+	Name      string
+	Labels    map[string]string
+	Attempt   int
+	// Outcome is set once the handler has finished.
+	Outcome Outcome
+}
+
+// RunnerObserver is notified about the lifecycle of a run. Callbacks are
+// invoked synchronously from the handler goroutines, so they must be safe for
+// concurrent use and should not block. Embed NopObserver to implement a subset.
+type RunnerObserver interface {
+	OnRunStart(ctx context.Context, run RunEvent)
+	OnHandlerStart(ctx context.Context, e HandlerEvent)
+	OnHandlerWaiting(ctx context.Context, e HandlerEvent, on int)
+	OnHandlerFinish(ctx context.Context, e HandlerEvent, result any, err error, d time.Duration)
+	OnHandlerPanic(ctx context.Context, e HandlerEvent, recovered any)
+	OnCancel(ctx context.Context, run RunEvent, cause error)
+	OnRunFinish(ctx context.Context, run RunEvent, err error, d time.Duration)
+}
+
+type NopObserver struct{}
+
+func (NopObserver) OnRunStart(context.Context, RunEvent)                                     {}
+func (NopObserver) OnHandlerStart(context.Context, HandlerEvent)                             {}
+func (NopObserver) OnHandlerWaiting(context.Context, HandlerEvent, int)                      {}
+func (NopObserver) OnHandlerFinish(context.Context, HandlerEvent, any, error, time.Duration) {}
+func (NopObserver) OnHandlerPanic(context.Context, HandlerEvent, any)                        {}
+func (NopObserver) OnCancel(context.Context, RunEvent, error)                                {}
+func (NopObserver) OnRunFinish(context.Context, RunEvent, error, time.Duration)              {}
+
+type RunnerOption[S Store] func(*Runner[S])
+
+func WithObserver[S Store](o RunnerObserver) RunnerOption[S] {
+	return func(r *Runner[S]) {
+		r.observers = append(r.observers, o)
+	}
+}
+
+type observers []RunnerObserver
+
+func (os observers) OnRunStart(ctx context.Context, run RunEvent) {
+	for _, o := range os {
+		o.OnRunStart(ctx, run)
+	}
+}
+
+func (os observers) OnHandlerStart(ctx context.Context, e HandlerEvent) {
+	for _, o := range os {
+		o.OnHandlerStart(ctx, e)
+	}
+}
+
+func (os observers) OnHandlerWaiting(ctx context.Context, e HandlerEvent, on int) {
+	for _, o := range os {
+		o.OnHandlerWaiting(ctx, e, on)
+	}
+}
+
+func (os observers) OnHandlerFinish(ctx context.Context, e HandlerEvent, result any, err error, d time.Duration) {
+	for _, o := range os {
+		o.OnHandlerFinish(ctx, e, result, err, d)
+	}
+}
+
+func (os observers) OnHandlerPanic(ctx context.Context, e HandlerEvent, recovered any) {
+	for _, o := range os {
+		o.OnHandlerPanic(ctx, e, recovered)
+	}
+}
+
+func (os observers) OnCancel(ctx context.Context, run RunEvent, cause error) {
+	for _, o := range os {
+		o.OnCancel(ctx, run, cause)
+	}
+}
+
+func (os observers) OnRunFinish(ctx context.Context, run RunEvent, err error, d time.Duration) {
+	for _, o := range os {
+		o.OnRunFinish(ctx, run, err, d)
+	}
+}
+
+func newRunId() string {
+	return randomHex(8)
+}
@@ -0,0 +1,128 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordingObserver struct {
+	NopObserver
+
+	mu     sync.Mutex
+	events []string
+}
+
+func (o *recordingObserver) record(format string, args ...any) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, fmt.Sprintf(format, args...))
+}
+
+func (o *recordingObserver) OnRunStart(context.Context, RunEvent) {
+	o.record("run start")
+}
+
+func (o *recordingObserver) OnHandlerStart(_ context.Context, e HandlerEvent) {
+	o.record("start %d", e.HandlerId)
+}
+
+func (o *recordingObserver) OnHandlerWaiting(_ context.Context, e HandlerEvent, on int) {
+	o.record("waiting %d on %d", e.HandlerId, on)
+}
+
+func (o *recordingObserver) OnHandlerFinish(_ context.Context, e HandlerEvent, result any, err error, _ time.Duration) {
+	o.record("finish %d: %v, %v", e.HandlerId, result, err)
+}
+
+func (o *recordingObserver) OnHandlerPanic(_ context.Context, e HandlerEvent, recovered any) {
+	o.record("panic %d: %v", e.HandlerId, recovered)
+}
+
+func (o *recordingObserver) OnCancel(_ context.Context, _ RunEvent, cause error) {
+	o.record("cancel: %v", errors.Is(cause, ErrCriticalPath))
+}
+
+func (o *recordingObserver) OnRunFinish(_ context.Context, _ RunEvent, err error, _ time.Duration) {
+	o.record("run finish: %v", err != nil)
+}
+
+func (o *recordingObserver) Events() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return slices.Clone(o.events)
+}
+
+func Test_Runner_Run_WithObserver(t *testing.T) {
+	t.Parallel()
+
+	const (
+		handlerId1 = 1
+		handlerId2 = 2
+	)
+
+	s := NewStore()
+	require.NoError(t, errors.Join(s.Register(handlerId1), s.Register(handlerId2)))
+
+	o := &recordingObserver{}
+	r := NewRunner(WithObserver[Store](o))
+	require.NoError(t, errors.Join(
+		r.Register(handlerId1, func(context.Context, Store) (any, error) {
+			time.Sleep(time.Millisecond * 50)
+			return "foobar", nil
+		}),
+		r.Register(handlerId2, func(context.Context, Store) (any, error) {
+			return nil, nil
+		}, WithRunAfter[Store](handlerId1)),
+	))
+	require.NoError(t, r.Run(context.Background(), s))
+
+	events := o.Events()
+	require.Equal(t, "run start", events[0])
+	require.Equal(t, "run finish: false", events[len(events)-1])
+	require.ElementsMatch(t, []string{
+		"run start",
+		"start 1",
+		"start 2",
+		"waiting 2 on 1",
+		"finish 1: foobar, <nil>",
+		"finish 2: <nil>, <nil>",
+		"run finish: false",
+	}, events)
+	require.Less(t, slices.Index(events, "finish 1: foobar, <nil>"), slices.Index(events, "finish 2: <nil>, <nil>"))
+}
+
+func Test_Runner_Run_WithObserver_PanicAndCancel(t *testing.T) {
+	t.Parallel()
+
+	const (
+		handlerId1 = 1
+		handlerId2 = 2
+	)
+
+	s := NewStore()
+	require.NoError(t, errors.Join(s.Register(handlerId1), s.Register(handlerId2)))
+
+	o := &recordingObserver{}
+	r := NewRunner(WithObserver[Store](o))
+	require.NoError(t, errors.Join(
+		r.Register(handlerId1, func(context.Context, Store) (any, error) {
+			panic("panic in handler")
+		}),
+		r.Register(handlerId2, func(context.Context, Store) (any, error) {
+			return nil, errors.New("error in handler")
+		}, WithCriticalPath[Store]()),
+	))
+	require.Error(t, r.Run(context.Background(), s))
+
+	events := o.Events()
+	require.Contains(t, events, "panic 1: panic in handler")
+	require.Contains(t, events, "cancel: true")
+	require.Equal(t, "run finish: true", events[len(events)-1])
+}
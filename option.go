@@ -3,56 +3,141 @@ package pipes
 import (
 	"context"
 	"errors"
+	"maps"
+	"slices"
+	"sync/atomic"
 	"time"
 )
 
 var (
-	ErrSkip         = errors.New("handler was skipped")
-	ErrCriticalPath = errors.New("failure on critical path")
+	ErrSkip          = errors.New("handler was skipped")
+	ErrCriticalPath  = errors.New("failure on critical path")
+	ErrPartialResult = errors.New("partial result")
 )
 
-type Option[S Store] func(Handler[S]) Handler[S]
+// Option is passed to Runner.Register. annotate tells the runner about the
+// handler at registration time, wrap decorates the handler, either may be nil.
+type Option[S Store] struct {
+	descriptor   OptionDescriptor
+	annotate     func(*handlerSpec)
+	wrap         func(Handler[S]) Handler[S]
+	compensation Handler[S]
+}
+
+// WithMiddleware makes an option of a plain handler decorator.
+func WithMiddleware[S Store](wrap func(Handler[S]) Handler[S]) Option[S] {
+	return Option[S]{descriptor: OptionDescriptor{Kind: OptionCustom, Name: "WithMiddleware"}, wrap: wrap}
+}
+
+// Apply decorates h with the option, for handlers called outside a runner.
+func (o Option[S]) Apply(h Handler[S]) Handler[S] {
+	if o.wrap == nil {
+		return h
+	}
+	return o.wrap(h)
+}
 
 func WithTimeout[S Store](timeout time.Duration) Option[S] {
-	return func(next Handler[S]) Handler[S] {
-		return func(ctx context.Context, s S) (any, error) {
-			ctx, cancelFn := context.WithTimeout(ctx, timeout)
-			defer cancelFn()
-			return next(ctx, s)
-		}
+	return Option[S]{
+		descriptor: OptionDescriptor{Kind: OptionTimeout, Name: "WithTimeout", Params: map[string]any{"timeout": timeout}},
+		annotate:   func(spec *handlerSpec) { spec.timeout = timeout },
+		wrap: func(next Handler[S]) Handler[S] {
+			return func(ctx context.Context, s S) (any, error) {
+				timeoutCtx, cancelFn := context.WithTimeout(ctx, timeout)
+				defer cancelFn()
+				return next(withDeadlineSource(ctx, timeoutCtx, DeadlineSourceTimeout), s)
+			}
+		},
+	}
+}
+
+// WithSoftTimeout closes the SoftDeadline channel of the handler context after
+// timeout. A handler which returns without an error after that is recorded with
+// ErrPartialResult next to its data.
+func WithSoftTimeout[S Store](timeout time.Duration) Option[S] {
+	return Option[S]{
+		descriptor: OptionDescriptor{Kind: OptionSoftTimeout, Name: "WithSoftTimeout", Params: map[string]any{"timeout": timeout}},
+		annotate:   func(spec *handlerSpec) { spec.softTimeout = timeout },
+		wrap: func(next Handler[S]) Handler[S] {
+			return func(ctx context.Context, s S) (any, error) {
+				var fired atomic.Bool
+				soft := make(chan struct{})
+				timer := time.AfterFunc(timeout, func() {
+					fired.Store(true)
+					close(soft)
+				})
+
+				data, err := next(withSoftDeadline(ctx, soft), s)
+				if timer.Stop() || !fired.Load() || err != nil {
+					return data, err
+				}
+				return data, ErrPartialResult
+			}
+		},
 	}
 }
 
 func WithCondition[S Store](skip bool) Option[S] {
-	return func(next Handler[S]) Handler[S] {
-		return func(ctx context.Context, s S) (any, error) {
-			if skip {
-				return nil, ErrSkip
+	return Option[S]{
+		descriptor: OptionDescriptor{Kind: OptionCondition, Name: "WithCondition", Params: map[string]any{"skip": skip}},
+		annotate:   func(spec *handlerSpec) { spec.condition = &skip },
+		wrap: func(next Handler[S]) Handler[S] {
+			return func(ctx context.Context, s S) (any, error) {
+				if skip {
+					return nil, ErrSkip
+				}
+				return next(ctx, s)
 			}
-			return next(ctx, s)
-		}
+		},
 	}
 }
 
 func WithCriticalPath[S Store]() Option[S] {
-	return func(next Handler[S]) Handler[S] {
-		return func(ctx context.Context, s S) (any, error) {
-			data, err := next(ctx, s)
-			if err != nil {
-				return data, errors.Join(ErrCriticalPath, err)
+	return Option[S]{
+		descriptor: OptionDescriptor{Kind: OptionCriticalPath, Name: "WithCriticalPath"},
+		annotate:   func(spec *handlerSpec) { spec.critical = true },
+		wrap: func(next Handler[S]) Handler[S] {
+			return func(ctx context.Context, s S) (any, error) {
+				data, err := next(ctx, s)
+				if err != nil && !errors.Is(err, ErrPartialResult) {
+					return data, errors.Join(ErrCriticalPath, err)
+				}
+				return data, err
+			}
+		},
+	}
+}
+
+func WithName[S Store](name string) Option[S] {
+	return Option[S]{
+		descriptor: OptionDescriptor{Kind: OptionName, Name: "WithName", Params: map[string]any{"name": name}},
+		annotate:   func(spec *handlerSpec) { spec.name = name },
+	}
+}
+
+func WithLabels[S Store](labels map[string]string) Option[S] {
+	return Option[S]{
+		descriptor: OptionDescriptor{Kind: OptionLabels, Name: "WithLabels", Params: map[string]any{"labels": maps.Clone(labels)}},
+		annotate: func(spec *handlerSpec) {
+			if spec.labels == nil {
+				spec.labels = make(map[string]string, len(labels))
 			}
-			return data, err
-		}
+			maps.Copy(spec.labels, labels)
+		},
 	}
 }
 
 func WithRunAfter[S Store](handlerIds ...int) Option[S] {
-	return func(next Handler[S]) Handler[S] {
-		return func(ctx context.Context, s S) (any, error) {
-			for i := 0; i < len(handlerIds); i++ {
-				_, _ = s.Read(ctx, handlerIds[i])
+	return Option[S]{
+		descriptor: OptionDescriptor{Kind: OptionRunAfter, Name: "WithRunAfter", Params: map[string]any{"handler_ids": slices.Clone(handlerIds)}},
+		annotate:   func(spec *handlerSpec) { spec.addDeps(handlerIds...) },
+		wrap: func(next Handler[S]) Handler[S] {
+			return func(ctx context.Context, s S) (any, error) {
+				for i := 0; i < len(handlerIds); i++ {
+					_, _ = s.Read(ctx, handlerIds[i])
+				}
+				return next(ctx, s)
 			}
-			return next(ctx, s)
-		}
+		},
 	}
 }
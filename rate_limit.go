@@ -0,0 +1,108 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrInvalidRate = errors.New("rate must be positive")
+
+// RateLimiter blocks until a call is allowed or ctx is done. It is satisfied
+// by TokenBucket and by golang.org/x/time/rate.Limiter.
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+// TokenBucket is safe for concurrent use and meant to be shared by every
+// handler and runner calling the same API.
+type TokenBucket struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket allows rate calls per second on average and up to burst
+// calls at once.
+func NewTokenBucket(rate float64, burst int) (*TokenBucket, error) {
+	if !(rate > 0) {
+		return nil, fmt.Errorf("%w: %g", ErrInvalidRate, rate)
+	}
+
+	burst = max(burst, 1)
+	return &TokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}, nil
+}
+
+// Wait takes a token, waiting for it to be refilled if needed. A token
+// reserved by a call that gives up is returned to the bucket.
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	delay := b.reserve(time.Now())
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		return ctx.Err()
+	}
+}
+
+func (b *TokenBucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+func (b *TokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.burst, b.tokens+1)
+}
+
+// WithRateLimit waits for the limiter before every call of the handler. The
+// wait is reported as HandlerStats.Throttled rather than as execution time.
+func WithRateLimit[S Store](limiter RateLimiter) Option[S] {
+	return Option[S]{
+		descriptor: OptionDescriptor{Kind: OptionRateLimit, Name: "WithRateLimit"},
+		wrap: func(next Handler[S]) Handler[S] {
+			return func(ctx context.Context, s S) (any, error) {
+				start := time.Now()
+				err := limiter.Wait(ctx)
+				if e, ok := executionFrom(ctx); ok {
+					e.addThrottle(time.Since(start))
+				}
+				if err != nil {
+					return nil, err
+				}
+				return next(ctx, s)
+			}
+		},
+	}
+}
@@ -0,0 +1,105 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TokenBucket_Wait(t *testing.T) {
+	t.Parallel()
+
+	b, err := NewTokenBucket(20, 2)
+	require.NoError(t, err)
+
+	start := time.Now()
+	for range 4 {
+		require.NoError(t, b.Wait(context.Background()))
+	}
+	require.GreaterOrEqual(t, time.Since(start), time.Millisecond*90)
+}
+
+func Test_NewTokenBucket_InvalidRate(t *testing.T) {
+	t.Parallel()
+
+	for _, rate := range []float64{0, -1, math.NaN()} {
+		_, err := NewTokenBucket(rate, 1)
+		require.ErrorIs(t, err, ErrInvalidRate)
+	}
+}
+
+func Test_TokenBucket_Wait_Cancelled(t *testing.T) {
+	t.Parallel()
+
+	b, err := NewTokenBucket(1, 1)
+	require.NoError(t, err)
+	require.NoError(t, b.Wait(context.Background()))
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancelFn()
+	require.ErrorIs(t, b.Wait(ctx), context.DeadlineExceeded)
+
+	b.cancel()
+	require.ErrorIs(t, b.Wait(ctx), context.DeadlineExceeded)
+}
+
+func Test_Runner_Run_WithRateLimit(t *testing.T) {
+	t.Parallel()
+
+	const (
+		handlerId1 = 1
+		handlerId2 = 2
+	)
+
+	limiter, err := NewTokenBucket(10, 1)
+	require.NoError(t, err)
+	handler := func(context.Context, Store) (any, error) {
+		return nil, nil
+	}
+
+	s := NewStore()
+	require.NoError(t, errors.Join(s.Register(handlerId1), s.Register(handlerId2)))
+
+	r := NewRunner[Store]()
+	require.NoError(t, errors.Join(
+		r.Register(handlerId1, handler, WithRateLimit[Store](limiter)),
+		r.Register(handlerId2, handler, WithRateLimit[Store](limiter)),
+	))
+	require.NoError(t, r.Run(context.Background(), s))
+
+	stats := r.HandlerStatistics()
+	throttled := stats[handlerId1].Throttled + stats[handlerId2].Throttled
+	require.GreaterOrEqual(t, throttled, time.Millisecond*80)
+	require.Less(t, stats[handlerId1].Executing, time.Millisecond*50)
+	require.Less(t, stats[handlerId2].Executing, time.Millisecond*50)
+}
+
+func Test_Runner_Run_WithRateLimit_Cancelled(t *testing.T) {
+	t.Parallel()
+
+	const handlerId = 1
+
+	limiter, err := NewTokenBucket(0.1, 1)
+	require.NoError(t, err)
+	require.NoError(t, limiter.Wait(context.Background()))
+
+	var called bool
+	s := NewStore()
+	require.NoError(t, s.Register(handlerId))
+
+	r := NewRunner[Store]()
+	require.NoError(t, r.Register(handlerId, func(context.Context, Store) (any, error) {
+		called = true
+		return nil, nil
+	}, WithTimeout[Store](time.Millisecond*50), WithRateLimit[Store](limiter)))
+	require.NoError(t, r.Run(context.Background(), s))
+
+	_, err = s.Read(context.Background(), handlerId)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.False(t, called)
+	require.GreaterOrEqual(t, r.HandlerStatistics()[handlerId].Throttled, time.Millisecond*40)
+}
@@ -11,6 +11,9 @@ func NewRegistrator[S Store](
 	runner *Runner[S],
 ) Registrator[S] {
 	return func(handlerId int, handler Handler[S], opts ...Option[S]) error {
+		if _, err := runner.checkRegister(handlerId, opts); err != nil {
+			return err
+		}
 		if err := store.Register(handlerId); err != nil {
 			return err
 		}
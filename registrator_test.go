@@ -1,6 +1,7 @@
 package pipes
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -65,3 +66,25 @@ func Test_NewRegistrator(t *testing.T) {
 		})
 	}
 }
+
+func Test_NewRegistrator_IncompatibleOptions(t *testing.T) {
+	t.Parallel()
+
+	const handlerId = 1
+
+	s := NewStore()
+	r := NewRunner[Store]()
+	registrator := NewRegistrator(s, r)
+
+	err := registrator(handlerId, nil, WithCondition[Store](true), WithRunAfter[Store](2))
+	require.ErrorIs(t, err, ErrIncompatibleOptions)
+
+	require.NoError(t, registrator(handlerId, func(context.Context, Store) (any, error) {
+		return "data", nil
+	}))
+	require.NoError(t, r.Run(context.Background(), s))
+
+	data, err := s.Read(context.Background(), handlerId)
+	require.NoError(t, err)
+	require.Equal(t, "data", data)
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"maps"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -15,47 +17,221 @@ import (
 var (
 	ErrHandlerAlreadyRegistered    = errors.New("handler already registered")
 	ErrRunnerHasBeenLaunchedBefore = errors.New("runner has been launched before")
+	ErrCancelledByCritical         = errors.New("cancelled by failure on critical path")
 )
 
+// CancelledByCriticalError is recorded for handlers which stopped because
+// another handler failed on the critical path. It unwraps to
+// ErrCancelledByCritical, Err and the error of the failed handler.
+type CancelledByCriticalError struct {
+	// HandlerId and Name refer to the handler which failed on the critical path.
+	HandlerId int
+	Name      string
+	// Cause is the cause of the run context, it is not unwrapped, so the error
+	// does not match ErrCriticalPath. Err is what the handler returned.
+	Cause error
+	Err   error
+
+	upstream error
+}
+
+func (e *CancelledByCriticalError) Error() string {
+	return fmt.Sprintf("%s in %s: %v", ErrCancelledByCritical, describeHandler(e.HandlerId, e.Name), e.Err)
+}
+
+func (e *CancelledByCriticalError) Unwrap() []error {
+	if e.upstream == nil {
+		return []error{ErrCancelledByCritical, e.Err}
+	}
+	return []error{ErrCancelledByCritical, e.Err, e.upstream}
+}
+
+type criticalFailure struct {
+	handlerId int
+	name      string
+	err       error
+}
+
+func (e *criticalFailure) Error() string {
+	return fmt.Sprintf("%s: %v", describeHandler(e.handlerId, e.name), e.err)
+}
+
+func (e *criticalFailure) Unwrap() error {
+	return e.err
+}
+
+func cancelledByCritical(ctx context.Context, id int, err error) error {
+	if !errors.Is(err, context.Canceled) || errors.Is(err, ErrCancelledByCritical) {
+		return err
+	}
+
+	cause := context.Cause(ctx)
+	var failure *criticalFailure
+	if !errors.As(cause, &failure) || failure.handlerId == id {
+		return err
+	}
+	return &CancelledByCriticalError{
+		HandlerId: failure.handlerId,
+		Name:      failure.name,
+		Cause:     cause,
+		Err:       err,
+		upstream:  withoutCriticalPath(failure.err),
+	}
+}
+
+// withoutCriticalPath drops the ErrCriticalPath joined in by WithCriticalPath,
+// also when other options joined more errors around it.
+func withoutCriticalPath(err error) error {
+	if err == ErrCriticalPath {
+		return nil
+	}
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return err
+	}
+
+	var errs []error
+	for _, e := range joined.Unwrap() {
+		errs = append(errs, withoutCriticalPath(e))
+	}
+	return errors.Join(errs...)
+}
+
 type Handler[S Store] func(context.Context, S) (any, error)
 
 type Runner[S Store] struct {
-	handlers map[int]Handler[S]
+	handlers      map[int]Handler[S]
+	specs         map[int]*handlerSpec
+	compensations map[int]Handler[S]
+	finalizers    []finalizer[S]
 
-	statistics   map[int]time.Duration
-	statisticsMu sync.Mutex
+	statistics       map[int]time.Duration
+	executions       map[int]*execution
+	compensationErrs map[int]error
+	statisticsMu     sync.Mutex
+
+	observers observers
+	logger    *slog.Logger
+	tracer    Tracer
+	budget    time.Duration
+	runId     string
 
 	done atomic.Bool
 }
 
-func NewRunner[S Store]() *Runner[S] {
-	return &Runner[S]{
-		handlers:   make(map[int]Handler[S]),
-		statistics: make(map[int]time.Duration),
+func NewRunner[S Store](opts ...RunnerOption[S]) *Runner[S] {
+	r := &Runner[S]{
+		handlers:         make(map[int]Handler[S]),
+		specs:            make(map[int]*handlerSpec),
+		compensations:    make(map[int]Handler[S]),
+		statistics:       make(map[int]time.Duration),
+		executions:       make(map[int]*execution),
+		compensationErrs: make(map[int]error),
+		runId:            newRunId(),
+		tracer:           noopTracer{},
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
+	return r
 }
 
 func (r *Runner[S]) Register(id int, h Handler[S], opts ...Option[S]) error {
-	if _, ok := r.handlers[id]; ok {
-		return ErrHandlerAlreadyRegistered
+	spec, err := r.checkRegister(id, opts)
+	if err != nil {
+		return err
 	}
 	r.handlers[id] = wrap(h, opts)
+	r.specs[id] = spec
+	for _, opt := range slices.Backward(opts) {
+		if opt.compensation != nil {
+			r.compensations[id] = opt.compensation
+		}
+	}
 	return nil
 }
 
-func (r *Runner[S]) Run(ctx context.Context, s S) error {
+// checkRegister tells whether Register would accept the handler.
+func (r *Runner[S]) checkRegister(id int, opts []Option[S]) (*handlerSpec, error) {
+	if _, ok := r.handlers[id]; ok {
+		return nil, ErrHandlerAlreadyRegistered
+	}
+	spec := newHandlerSpec(id, opts)
+	if err := spec.check(); err != nil {
+		return nil, fmt.Errorf("%s: %w", describeHandler(id, spec.name), err)
+	}
+	return spec, nil
+}
+
+func (r *Runner[S]) Run(ctx context.Context, s S) (err error) {
 	if !r.done.CompareAndSwap(false, true) {
 		return ErrRunnerHasBeenLaunchedBefore
 	}
 
+	run := RunEvent{RunId: r.runId, Start: time.Now()}
+
+	ctx, runSpan := r.tracer.Start(ctx, "run")
+	runSpan.SetAttribute("run_id", r.runId)
+	defer func() { runSpan.End(err) }()
+
+	if r.budget > 0 {
+		budgetCtx, cancelFn := context.WithTimeout(ctx, r.budget)
+		defer cancelFn()
+		ctx = withDeadlineSource(ctx, budgetCtx, DeadlineSourceBudget)
+	}
+
 	eg := errgroup.Group{}
 	ctx, cancelFn := context.WithCancelCause(ctx)
 	defer cancelFn(nil)
 
+	r.observers.OnRunStart(ctx, run)
+	cancelled := make(chan struct{})
+	stopCancelHook := context.AfterFunc(ctx, func() {
+		defer close(cancelled)
+		r.observers.OnCancel(ctx, run, context.Cause(ctx))
+	})
+
 	var killSwitch atomic.Bool
 
+	names := make(map[int]string, len(r.specs))
+	for id, spec := range r.specs {
+		names[id] = spec.name
+	}
+
+	spans := make(map[int]Span, len(r.handlers))
+	spanCtxs := make(map[int]context.Context, len(r.handlers))
+	for id := range r.handlers {
+		spec := r.specs[id]
+		spanCtxs[id], spans[id] = r.tracer.Start(ctx, spec.displayName())
+		spans[id].SetAttribute("handler_id", id)
+		for k, v := range spec.labels {
+			spans[id].SetAttribute("label."+k, v)
+		}
+	}
+
 	for id, handler := range r.handlers {
+		spec := r.specs[id]
+		exec := &execution{
+			handlerId: id,
+			name:      spec.name,
+			labels:    spec.labels,
+			runId:     r.runId,
+			observer:  r.observers,
+			names:     names,
+		}
+		r.statisticsMu.Lock()
+		r.executions[id] = exec
+		r.statisticsMu.Unlock()
+
 		eg.Go(func() (err error) {
+			exec.begin()
+			ctx := withExecution(spanCtxs[id], exec)
+			defer endHandlerSpan(spans, id, exec)
+			if r.logger != nil {
+				ctx = withLogger(ctx, r.logger.With(handlerAttrs(exec.event())...))
+			}
+			r.observers.OnHandlerStart(ctx, exec.event())
+
 			defer func(from time.Time) {
 				r.statisticsMu.Lock()
 				defer r.statisticsMu.Unlock()
@@ -64,26 +240,49 @@ func (r *Runner[S]) Run(ctx context.Context, s S) error {
 
 			defer func() {
 				if recErr := recover(); recErr != nil {
-					err = errors.Join(err, fmt.Errorf("panic recover: %v", recErr))
+					err = errors.Join(err, fmt.Errorf("panic recover in %s: %v", describeHandler(id, spec.name), recErr), exec.deferredErr())
+					exec.finish(OutcomePanic, err)
+					r.observers.OnHandlerPanic(ctx, exec.event(), recErr)
+					r.observers.OnHandlerFinish(ctx, exec.event(), nil, err, exec.duration())
 					wErr := s.Write(id, nil, err)
 					err = errors.Join(err, wErr)
 				}
 			}()
 
-			d, e := handler(ctx, s)
-			if errors.Is(e, ErrCriticalPath) {
+			d, e := r.runHandler(ctx, s, spec, handler)
+			var upstream *criticalFailure
+			if errors.Is(e, ErrCriticalPath) && !errors.As(e, &upstream) {
+				e = &criticalFailure{handlerId: id, name: spec.name, err: e}
 				if killSwitch.CompareAndSwap(false, true) {
 					cancelFn(e)
 				}
 				err = errors.Join(err, e)
+			} else {
+				e = cancelledByCritical(ctx, id, e)
 			}
 
+			exec.finish(outcomeOf(e), e)
+			r.observers.OnHandlerFinish(ctx, exec.event(), d, e, exec.duration())
+
 			err = errors.Join(err, s.Write(id, d, e))
 			return err
 		})
 	}
 
-	return eg.Wait()
+	err = eg.Wait()
+	if !stopCancelHook() {
+		<-cancelled
+	}
+	if killSwitch.Load() {
+		r.compensate(ctx, s)
+	}
+	err = errors.Join(err, r.finalize(ctx, s))
+	r.observers.OnRunFinish(ctx, run, err, time.Since(run.Start))
+	return err
+}
+
+func (r *Runner[S]) RunId() string {
+	return r.runId
 }
 
 func (r *Runner[S]) Statistics() map[int]time.Duration {
@@ -92,14 +291,67 @@ func (r *Runner[S]) Statistics() map[int]time.Duration {
 	return maps.Clone(r.statistics)
 }
 
+func (r *Runner[S]) HandlerStatistics() map[int]HandlerStats {
+	r.statisticsMu.Lock()
+	defer r.statisticsMu.Unlock()
+
+	result := make(map[int]HandlerStats, len(r.executions))
+	for id, exec := range r.executions {
+		result[id] = exec.stats()
+	}
+	return result
+}
+
+func (r *Runner[S]) CacheStatistics() map[int]CacheStats {
+	r.statisticsMu.Lock()
+	defer r.statisticsMu.Unlock()
+
+	result := make(map[int]CacheStats)
+	for id, exec := range r.executions {
+		exec.mu.Lock()
+		if exec.hasCache {
+			result[id] = exec.cache
+		}
+		exec.mu.Unlock()
+	}
+	return result
+}
+
+func (r *Runner[S]) runHandler(ctx context.Context, s S, spec *handlerSpec, handler Handler[S]) (any, error) {
+	ctx, cancelFn, err := r.allocateBudget(ctx, s, spec)
+	defer cancelFn()
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, s)
+}
+
+func endHandlerSpan(spans map[int]Span, id int, exec *execution) {
+	stats := exec.stats()
+
+	span := spans[id]
+	span.SetAttribute("outcome", stats.Outcome.String())
+	span.SetAttribute("attempts", stats.Attempts)
+
+	var linked []int
+	for _, w := range stats.Waits {
+		if upstream, ok := spans[w.HandlerId]; ok && !slices.Contains(linked, w.HandlerId) {
+			span.AddLink(upstream.SpanContext())
+			linked = append(linked, w.HandlerId)
+		}
+	}
+
+	span.End(stats.Err)
+}
+
 func wrap[S Store](h Handler[S], opts []Option[S]) Handler[S] {
 	if len(opts) == 0 {
 		return h
 	}
 
-	handler := opts[len(opts)-1](h)
+	handler := opts[len(opts)-1].Apply(h)
 	for i := len(opts) - 2; i >= 0; i-- {
-		handler = opts[i](handler)
+		handler = opts[i].Apply(handler)
 	}
 	return handler
 }
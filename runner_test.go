@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -394,12 +395,12 @@ func Test_wrap(t *testing.T) {
 	}
 
 	option := func(value int) Option[Store] {
-		return func(next Handler[Store]) Handler[Store] {
+		return WithMiddleware(func(next Handler[Store]) Handler[Store] {
 			return func(ctx context.Context, s Store) (any, error) {
 				result = append(result, value)
 				return next(ctx, s)
 			}
-		}
+		})
 	}
 
 	s := NewStore()
@@ -420,6 +421,37 @@ func Test_wrap(t *testing.T) {
 	require.Equal(t, []int{3, 2, 1, 0}, result)
 }
 
+func Test_Runner_Register_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	handler := func(context.Context, Store) (any, error) {
+		return nil, nil
+	}
+
+	var (
+		wg      sync.WaitGroup
+		runners = make([]*Runner[Store], 10)
+		errs    = make([]error, 2*len(runners))
+	)
+	for i := range runners {
+		runners[i] = NewRunner[Store]()
+		wg.Go(func() {
+			errs[2*i] = runners[i].Register(1, handler, WithName[Store](fmt.Sprint(i)))
+		})
+		wg.Go(func() {
+			_, errs[2*i+1] = WithTimeout[Store](time.Second).Apply(handler)(context.Background(), NewStore())
+		})
+	}
+	wg.Wait()
+
+	require.NoError(t, errors.Join(errs...))
+	for i, r := range runners {
+		require.Equal(t, []OptionDescriptor{
+			{Kind: OptionName, Name: "WithName", Params: map[string]any{"name": fmt.Sprint(i)}},
+		}, r.HandlerOptions()[1])
+	}
+}
+
 func Test_Runner_Run_MultipleCalls(t *testing.T) {
 	t.Parallel()
 
@@ -432,3 +464,234 @@ func Test_Runner_Run_MultipleCalls(t *testing.T) {
 	err = r.Run(context.Background(), s)
 	require.ErrorIs(t, err, ErrRunnerHasBeenLaunchedBefore)
 }
+
+func Test_Runner_Run_WithName(t *testing.T) {
+	t.Parallel()
+
+	const (
+		handlerId1 = 1
+		handlerId2 = 2
+	)
+
+	s := NewStore()
+	require.NoError(t, errors.Join(s.Register(handlerId1), s.Register(handlerId2)))
+
+	r := NewRunner[Store]()
+	err := errors.Join(
+		r.Register(handlerId1, func(context.Context, Store) (any, error) {
+			return 42, nil
+		}, WithName[Store]("fetch"), WithLabels[Store](map[string]string{"team": "core"})),
+		r.Register(handlerId2, func(ctx context.Context, s Store) (any, error) {
+			return Read[string](ctx, s, handlerId1)
+		}, WithName[Store]("process"), WithCriticalPath[Store]()),
+	)
+	require.NoError(t, err)
+
+	err = r.Run(context.Background(), s)
+	require.ErrorIs(t, err, ErrCriticalPath)
+	require.ErrorContains(t, err, "handler 2 (process): failure on critical path")
+	require.ErrorContains(t, err, "invalid type string for data from handler 1 (fetch)")
+
+	statistics := r.HandlerStatistics()
+	require.Equal(t, "fetch", statistics[handlerId1].Name)
+	require.Equal(t, map[string]string{"team": "core"}, statistics[handlerId1].Labels)
+	require.Equal(t, "process", statistics[handlerId2].DisplayName())
+}
+
+func Test_Runner_Run_WithName_PanicHandler(t *testing.T) {
+	t.Parallel()
+
+	const handlerId = 3
+
+	s := NewStore()
+	require.NoError(t, s.Register(handlerId))
+
+	r := NewRunner[Store]()
+	err := r.Register(handlerId, func(context.Context, Store) (any, error) {
+		panic("panic in handler")
+	}, WithName[Store]("notify"))
+	require.NoError(t, err)
+
+	err = r.Run(context.Background(), s)
+	require.ErrorContains(t, err, "panic recover in handler 3 (notify): panic in handler")
+}
+
+func Test_Runner_Run_CancelledByCritical(t *testing.T) {
+	t.Parallel()
+
+	const (
+		handlerId1 = 1
+		handlerId2 = 2
+		handlerId3 = 3
+	)
+
+	errUpstream := errors.New("error in handler")
+
+	s := NewStore()
+	require.NoError(t, errors.Join(s.Register(handlerId1), s.Register(handlerId2), s.Register(handlerId3)))
+
+	r := NewRunner[Store]()
+	err := errors.Join(
+		r.Register(handlerId1, func(context.Context, Store) (any, error) {
+			time.Sleep(time.Millisecond * 50)
+			return nil, errUpstream
+		}, WithName[Store]("charge"), WithCriticalPath[Store]()),
+		r.Register(handlerId2, func(ctx context.Context, _ Store) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}),
+		r.Register(handlerId3, func(ctx context.Context, s Store) (any, error) {
+			return s.Read(ctx, handlerId2)
+		}),
+	)
+	require.NoError(t, err)
+
+	err = r.Run(context.Background(), s)
+	require.ErrorIs(t, err, ErrCriticalPath)
+	require.NotErrorIs(t, err, ErrCancelledByCritical)
+
+	_, err = s.Read(context.Background(), handlerId1)
+	require.ErrorIs(t, err, ErrCriticalPath)
+	require.NotErrorIs(t, err, ErrCancelledByCritical)
+
+	for _, id := range []int{handlerId2, handlerId3} {
+		_, err = s.Read(context.Background(), id)
+		require.ErrorIs(t, err, ErrCancelledByCritical)
+		require.ErrorIs(t, err, context.Canceled)
+		require.ErrorIs(t, err, errUpstream)
+		require.NotErrorIs(t, err, ErrCriticalPath)
+		require.EqualError(t, err, "cancelled by failure on critical path in handler 1 (charge): context canceled")
+
+		var cancelled *CancelledByCriticalError
+		require.ErrorAs(t, err, &cancelled)
+		require.Equal(t, handlerId1, cancelled.HandlerId)
+		require.ErrorContains(t, cancelled.Cause, "error in handler")
+	}
+}
+
+func Test_Runner_Run_CancelledByCritical_ReturnedDownstream(t *testing.T) {
+	t.Parallel()
+
+	const (
+		handlerId1 = 1
+		handlerId2 = 2
+		handlerId3 = 3
+		handlerId4 = 4
+	)
+
+	s := NewStore()
+	require.NoError(t, errors.Join(s.Register(handlerId1), s.Register(handlerId2), s.Register(handlerId3), s.Register(handlerId4)))
+
+	readAfterRun := func(id int) Handler[Store] {
+		return func(ctx context.Context, s Store) (any, error) {
+			return s.Read(context.WithoutCancel(ctx), id)
+		}
+	}
+
+	r := NewRunner[Store]()
+	err := errors.Join(
+		r.Register(handlerId1, func(context.Context, Store) (any, error) {
+			time.Sleep(time.Millisecond * 50)
+			return nil, errors.New("error in handler")
+		}, WithCriticalPath[Store]()),
+		r.Register(handlerId2, func(ctx context.Context, _ Store) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}),
+		r.Register(handlerId3, readAfterRun(handlerId2)),
+		r.Register(handlerId4, readAfterRun(handlerId1)),
+	)
+	require.NoError(t, err)
+
+	err = r.Run(context.Background(), s)
+	require.ErrorIs(t, err, ErrCriticalPath)
+	require.NotContains(t, err.Error(), "handler 3")
+	require.NotContains(t, err.Error(), "handler 4")
+
+	_, err = s.Read(context.Background(), handlerId3)
+	require.ErrorIs(t, err, ErrCancelledByCritical)
+	require.NotErrorIs(t, err, ErrCriticalPath)
+	require.EqualError(t, err, "cancelled by failure on critical path in handler 1: context canceled")
+
+	_, err = s.Read(context.Background(), handlerId4)
+	var failure *criticalFailure
+	require.ErrorAs(t, err, &failure)
+	require.Equal(t, handlerId1, failure.handlerId)
+}
+
+func Test_Runner_Run_WithSoftTimeout(t *testing.T) {
+	t.Parallel()
+
+	const handlerId = 52
+
+	handler := func(delay time.Duration) Handler[Store] {
+		return func(ctx context.Context, _ Store) (any, error) {
+			var xs []int
+			for i := 0; ; i++ {
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-SoftDeadline(ctx):
+					return xs, nil
+				case <-time.After(delay):
+					xs = append(xs, i)
+					if len(xs) == 3 {
+						return xs, nil
+					}
+				}
+			}
+		}
+	}
+
+	tcs := []struct {
+		name    string
+		delay   time.Duration
+		partial bool
+	}{
+		{"partial result", time.Millisecond * 40, true},
+		{"complete result", time.Millisecond, false},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := NewStore()
+			require.NoError(t, s.Register(handlerId))
+
+			r := NewRunner[Store]()
+			err := r.Register(handlerId, handler(tc.delay),
+				WithCriticalPath[Store](),
+				WithTimeout[Store](time.Second),
+				WithSoftTimeout[Store](time.Millisecond*100),
+			)
+			require.NoError(t, err)
+			require.NoError(t, r.Run(context.Background(), s))
+
+			data, err := Read[[]int](context.Background(), s, handlerId)
+			if tc.partial {
+				require.ErrorIs(t, err, ErrPartialResult)
+				require.Equal(t, []int{0, 1}, data)
+				require.Equal(t, OutcomePartial, r.HandlerStatistics()[handlerId].Outcome)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, []int{0, 1, 2}, data)
+				require.Equal(t, OutcomeSuccess, r.HandlerStatistics()[handlerId].Outcome)
+			}
+		})
+	}
+}
+
+func Test_withoutCriticalPath(t *testing.T) {
+	t.Parallel()
+
+	errHandler := errors.New("error in handler")
+
+	require.Nil(t, withoutCriticalPath(ErrCriticalPath))
+	require.Equal(t, errHandler, withoutCriticalPath(errHandler))
+
+	err := withoutCriticalPath(errors.Join(errors.Join(ErrCriticalPath, errHandler), errors.New("error in teardown")))
+	require.ErrorIs(t, err, errHandler)
+	require.NotErrorIs(t, err, ErrCriticalPath)
+	require.EqualError(t, err, "error in handler\nerror in teardown")
+}
@@ -0,0 +1,70 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// WithSetup runs fn before the handler, which gets the context returned by fn,
+// so resources acquired by fn can be passed along as context values. The
+// handler does not run if fn fails. WithTeardown must be passed after it to see
+// the context returned by fn.
+func WithSetup[S Store](fn func(context.Context, S) (context.Context, error)) Option[S] {
+	return Option[S]{
+		descriptor: OptionDescriptor{Kind: OptionSetup, Name: "WithSetup"},
+		wrap: func(next Handler[S]) Handler[S] {
+			return func(ctx context.Context, s S) (any, error) {
+				ctx, err := fn(ctx, s)
+				if err != nil {
+					return nil, fmt.Errorf("setup: %w", err)
+				}
+				return next(ctx, s)
+			}
+		},
+	}
+}
+
+// WithTeardown runs fn after the handler however it finished, including a
+// panic or an expired timeout, with the error of the handler. The context of fn
+// is not cancelled with the handler. Errors of fn are joined into the error
+// recorded for the handler. Register rejects it outside WithSetup, where it
+// would miss the context of the setup and run after a failed one.
+func WithTeardown[S Store](fn func(context.Context, S, error) error) Option[S] {
+	return Option[S]{
+		descriptor: OptionDescriptor{Kind: OptionTeardown, Name: "WithTeardown"},
+		wrap: func(next Handler[S]) Handler[S] {
+			return func(ctx context.Context, s S) (data any, err error) {
+				defer func() {
+					recovered := recover()
+					if recovered == nil {
+						err = errors.Join(err, teardown(ctx, s, fn, err))
+						return
+					}
+
+					if tErr := teardown(ctx, s, fn, fmt.Errorf("panic: %v", recovered)); tErr != nil {
+						if e, ok := executionFrom(ctx); ok {
+							e.addDeferredErr(tErr)
+						}
+					}
+					panic(recovered)
+				}()
+
+				return next(ctx, s)
+			}
+		},
+	}
+}
+
+func teardown[S Store](ctx context.Context, s S, fn func(context.Context, S, error) error, err error) (tErr error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			tErr = fmt.Errorf("panic recover in teardown: %v", recovered)
+		}
+	}()
+
+	if err := fn(context.WithoutCancel(ctx), s, err); err != nil {
+		return fmt.Errorf("teardown: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type setupKey struct{}
+
+func Test_Runner_Run_WithSetup_WithTeardown(t *testing.T) {
+	t.Parallel()
+
+	const handlerId = 1
+
+	var (
+		tornDown    any
+		teardownErr error
+	)
+	s := NewStore()
+	require.NoError(t, s.Register(handlerId))
+
+	r := NewRunner[Store]()
+	require.NoError(t, r.Register(handlerId, func(ctx context.Context, _ Store) (any, error) {
+		return ctx.Value(setupKey{}), nil
+	},
+		WithSetup(func(ctx context.Context, _ Store) (context.Context, error) {
+			return context.WithValue(ctx, setupKey{}, "tx"), nil
+		}),
+		WithTeardown(func(ctx context.Context, _ Store, err error) error {
+			tornDown, teardownErr = ctx.Value(setupKey{}), err
+			return errors.New("error in teardown")
+		}),
+	))
+	require.NoError(t, r.Run(context.Background(), s))
+
+	data, err := s.Read(context.Background(), handlerId)
+	require.Equal(t, "tx", data)
+	require.EqualError(t, err, "teardown: error in teardown")
+	require.Equal(t, "tx", tornDown)
+	require.NoError(t, teardownErr)
+}
+
+func Test_Runner_Run_WithSetup_Error(t *testing.T) {
+	t.Parallel()
+
+	const handlerId = 1
+
+	var called bool
+	s := NewStore()
+	require.NoError(t, s.Register(handlerId))
+
+	r := NewRunner[Store]()
+	require.NoError(t, r.Register(handlerId, func(context.Context, Store) (any, error) {
+		called = true
+		return nil, nil
+	}, WithSetup(func(ctx context.Context, _ Store) (context.Context, error) {
+		return ctx, errors.New("error in setup")
+	})))
+	require.NoError(t, r.Run(context.Background(), s))
+
+	_, err := s.Read(context.Background(), handlerId)
+	require.EqualError(t, err, "setup: error in setup")
+	require.False(t, called)
+}
+
+func Test_Runner_Run_WithTeardown_Timeout(t *testing.T) {
+	t.Parallel()
+
+	const handlerId = 1
+
+	var ctxErr, teardownErr error
+	s := NewStore()
+	require.NoError(t, s.Register(handlerId))
+
+	r := NewRunner[Store]()
+	require.NoError(t, r.Register(handlerId, func(ctx context.Context, _ Store) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	},
+		WithTeardown(func(ctx context.Context, _ Store, err error) error {
+			ctxErr, teardownErr = ctx.Err(), err
+			return errors.New("error in teardown")
+		}),
+		WithTimeout[Store](time.Millisecond*10),
+	))
+	require.NoError(t, r.Run(context.Background(), s))
+
+	_, err := s.Read(context.Background(), handlerId)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorContains(t, err, "teardown: error in teardown")
+	require.NoError(t, ctxErr)
+	require.ErrorIs(t, teardownErr, context.DeadlineExceeded)
+}
+
+func Test_Runner_Run_WithTeardown_Panic(t *testing.T) {
+	t.Parallel()
+
+	const handlerId = 1
+
+	var teardownErr error
+	s := NewStore()
+	require.NoError(t, s.Register(handlerId))
+
+	r := NewRunner[Store]()
+	require.NoError(t, r.Register(handlerId, func(context.Context, Store) (any, error) {
+		panic("panic in handler")
+	}, WithTeardown(func(_ context.Context, _ Store, err error) error {
+		teardownErr = err
+		return errors.New("error in teardown")
+	})))
+
+	err := r.Run(context.Background(), s)
+	require.ErrorContains(t, err, "panic recover in handler 1: panic in handler")
+
+	_, err = s.Read(context.Background(), handlerId)
+	require.ErrorContains(t, err, "panic recover in handler 1: panic in handler")
+	require.ErrorContains(t, err, "teardown: error in teardown")
+	require.EqualError(t, teardownErr, "panic: panic in handler")
+}
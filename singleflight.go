@@ -0,0 +1,129 @@
+package pipes
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// flight is a call shared by the executions waiting for it.
+type flight struct {
+	done      chan struct{}
+	cancel    context.CancelFunc
+	data      any
+	err       error
+	recovered any
+
+	// guarded by SingleflightGroup.mu
+	waiters int
+	callers int
+}
+
+// SingleflightGroup is safe for concurrent use and meant to be shared by the
+// runners of one pipeline, runners of unrelated pipelines need their own group
+// as handler ids only mean something within a pipeline.
+type SingleflightGroup struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+func NewSingleflightGroup() *SingleflightGroup {
+	return &SingleflightGroup{flights: make(map[string]*flight)}
+}
+
+// WithSingleflight lets concurrent executions of the handler with the same key,
+// in any run sharing group, share a single call. The result is returned to
+// every caller, so each run still writes it into its own store. The shared call
+// keeps the deadline of the caller that started it and is cancelled once no
+// caller waits for it, a caller whose context is done stops waiting and later
+// callers start a new call.
+func WithSingleflight[S Store](group *SingleflightGroup, keyFn func(context.Context, S) (string, error)) Option[S] {
+	return Option[S]{
+		descriptor: OptionDescriptor{Kind: OptionSingleflight, Name: "WithSingleflight"},
+		wrap: func(next Handler[S]) Handler[S] {
+			return func(ctx context.Context, s S) (any, error) {
+				e, ok := executionFrom(ctx)
+				if !ok {
+					return next(ctx, s)
+				}
+
+				k, err := keyFn(ctx, s)
+				if err != nil {
+					return nil, err
+				}
+				key := fmt.Sprintf("%d\x00%s\x00%s", e.handlerId, e.name, k)
+
+				f := joinFlight(ctx, group, key, next, s)
+				select {
+				case <-ctx.Done():
+					group.leave(key, f)
+					return nil, ctx.Err()
+				case <-f.done:
+					if f.recovered != nil {
+						panic(f.recovered)
+					}
+					e.setShared(f.callers > 1)
+					return f.data, f.err
+				}
+			}
+		},
+	}
+}
+
+func joinFlight[S Store](ctx context.Context, g *SingleflightGroup, key string, next Handler[S], s S) *flight {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if f, ok := g.flights[key]; ok {
+		f.waiters++
+		f.callers++
+		return f
+	}
+
+	var (
+		callCtx  context.Context
+		cancelFn context.CancelFunc
+	)
+	if deadline, ok := ctx.Deadline(); ok {
+		callCtx, cancelFn = context.WithDeadline(context.WithoutCancel(ctx), deadline)
+	} else {
+		callCtx, cancelFn = context.WithCancel(context.WithoutCancel(ctx))
+	}
+
+	f := &flight{done: make(chan struct{}), cancel: cancelFn, waiters: 1, callers: 1}
+	g.flights[key] = f
+
+	go func() {
+		defer close(f.done)
+		defer cancelFn()
+		defer func() {
+			f.recovered = recover()
+			g.forget(key, f)
+		}()
+		f.data, f.err = next(callCtx, s)
+	}()
+	return f
+}
+
+// leave forgets the flight, so later callers do not join a call which is
+// already late for someone, and cancels it if nobody waits for it anymore.
+func (g *SingleflightGroup) leave(key string, f *flight) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.flights[key] == f {
+		delete(g.flights, key)
+	}
+	if f.waiters--; f.waiters == 0 {
+		f.cancel()
+	}
+}
+
+func (g *SingleflightGroup) forget(key string, f *flight) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.flights[key] == f {
+		delete(g.flights, key)
+	}
+}
@@ -0,0 +1,184 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Runner_Run_WithSingleflight(t *testing.T) {
+	t.Parallel()
+
+	const handlerId = 1
+
+	var calls atomic.Int32
+	handler := func(context.Context, Store) (any, error) {
+		calls.Add(1)
+		time.Sleep(time.Millisecond * 100)
+		return "response", nil
+	}
+	group := NewSingleflightGroup()
+	key := func(context.Context, Store) (string, error) {
+		return "key", nil
+	}
+
+	const runs = 5
+	var (
+		wg      sync.WaitGroup
+		stores  [runs]Store
+		runners [runs]*Runner[Store]
+		errs    [runs]error
+	)
+	for i := range runs {
+		stores[i] = NewStore()
+		require.NoError(t, stores[i].Register(handlerId))
+
+		runners[i] = NewRunner[Store]()
+		require.NoError(t, runners[i].Register(handlerId, handler, WithSingleflight[Store](group, key)))
+
+		wg.Go(func() {
+			errs[i] = runners[i].Run(context.Background(), stores[i])
+		})
+	}
+	wg.Wait()
+	require.NoError(t, errors.Join(errs[:]...))
+
+	require.Equal(t, int32(1), calls.Load())
+	for i := range runs {
+		data, err := stores[i].Read(context.Background(), handlerId)
+		require.NoError(t, err)
+		require.Equal(t, "response", data)
+		require.True(t, runners[i].HandlerStatistics()[handlerId].Shared)
+	}
+}
+
+func Test_Runner_Run_WithSingleflight_CallerCancelled(t *testing.T) {
+	t.Parallel()
+
+	const handlerId = 1
+
+	release := make(chan struct{})
+	handler := func(ctx context.Context, _ Store) (any, error) {
+		<-release
+		return "response", ctx.Err()
+	}
+	group := NewSingleflightGroup()
+	key := func(context.Context, Store) (string, error) {
+		return "key", nil
+	}
+
+	newRun := func(timeout time.Duration) func() (any, error) {
+		s := NewStore()
+		require.NoError(t, s.Register(handlerId))
+
+		r := NewRunner[Store]()
+		require.NoError(t, r.Register(handlerId, handler, WithTimeout[Store](timeout), WithSingleflight[Store](group, key)))
+		return func() (any, error) {
+			if err := r.Run(context.Background(), s); err != nil {
+				return nil, err
+			}
+			return s.Read(context.Background(), handlerId)
+		}
+	}
+
+	var (
+		wg     sync.WaitGroup
+		data   any
+		err    error
+		leader = newRun(time.Second)
+	)
+	wg.Go(func() { data, err = leader() })
+
+	time.Sleep(time.Millisecond * 20)
+	_, followerErr := newRun(time.Millisecond * 20)()
+	require.ErrorIs(t, followerErr, context.DeadlineExceeded)
+
+	close(release)
+	wg.Wait()
+	require.NoError(t, err)
+	require.Equal(t, "response", data)
+}
+
+func Test_Runner_Run_WithSingleflight_HangingCall(t *testing.T) {
+	t.Parallel()
+
+	const handlerId = 1
+
+	var calls atomic.Int32
+	finished := make(chan error, 2)
+	handler := func(ctx context.Context, _ Store) (any, error) {
+		calls.Add(1)
+		<-ctx.Done()
+		finished <- ctx.Err()
+		return nil, ctx.Err()
+	}
+	group := NewSingleflightGroup()
+	key := func(context.Context, Store) (string, error) {
+		return "key", nil
+	}
+
+	run := func(ctx context.Context, opts ...Option[Store]) error {
+		s := NewStore()
+		require.NoError(t, s.Register(handlerId))
+
+		r := NewRunner[Store]()
+		require.NoError(t, r.Register(handlerId, handler, append(opts, WithSingleflight[Store](group, key))...))
+		require.NoError(t, r.Run(ctx, s))
+
+		_, err := s.Read(context.Background(), handlerId)
+		return err
+	}
+
+	require.ErrorIs(t, run(context.Background(), WithTimeout[Store](time.Millisecond*30)), context.DeadlineExceeded)
+	require.Error(t, <-finished)
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	time.AfterFunc(time.Millisecond*30, cancelFn)
+	require.ErrorIs(t, run(ctx), context.Canceled)
+	require.ErrorIs(t, <-finished, context.Canceled)
+
+	require.Equal(t, int32(2), calls.Load())
+}
+
+func Test_Runner_Run_WithSingleflight_Groups(t *testing.T) {
+	t.Parallel()
+
+	const handlerId = 1
+
+	release := make(chan struct{})
+	key := func(context.Context, Store) (string, error) {
+		return "42", nil
+	}
+
+	run := func(group *SingleflightGroup, result string) <-chan any {
+		s := NewStore()
+		require.NoError(t, s.Register(handlerId))
+
+		r := NewRunner[Store]()
+		require.NoError(t, r.Register(handlerId, func(context.Context, Store) (any, error) {
+			<-release
+			return result, nil
+		}, WithSingleflight[Store](group, key)))
+
+		done := make(chan any, 1)
+		go func() {
+			_ = r.Run(context.Background(), s)
+			data, _ := s.Read(context.Background(), handlerId)
+			done <- data
+		}()
+		return done
+	}
+
+	users := run(NewSingleflightGroup(), "user 42")
+	orders := run(NewSingleflightGroup(), "order 42")
+	time.Sleep(time.Millisecond * 20)
+	close(release)
+
+	require.Equal(t, "user 42", <-users)
+	require.Equal(t, "order 42", <-orders)
+}
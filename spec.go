@@ -0,0 +1,71 @@
+package pipes
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// handlerSpec is what the runner knows about a handler at registration time,
+// options fill it in through Option.annotate.
+type handlerSpec struct {
+	id          int
+	name        string
+	labels      map[string]string
+	deps        []int
+	timeout     time.Duration
+	softTimeout time.Duration
+	critical    bool
+	condition   *bool
+
+	budgetShare float64
+	minBudget   time.Duration
+
+	hedgeDelay       time.Duration
+	hedgeMaxParallel int
+
+	options []OptionDescriptor
+}
+
+// newHandlerSpec annotates from the innermost option, the order wrap applies
+// them in, so the outermost one wins. Descriptors are kept in the order
+// options were passed.
+func newHandlerSpec[S Store](id int, opts []Option[S]) *handlerSpec {
+	spec := &handlerSpec{id: id}
+	for _, opt := range slices.Backward(opts) {
+		if opt.annotate != nil {
+			opt.annotate(spec)
+		}
+	}
+	for _, opt := range opts {
+		spec.options = append(spec.options, opt.descriptor)
+	}
+	return spec
+}
+
+func (s *handlerSpec) displayName() string {
+	return displayName(s.id, s.name)
+}
+
+func displayName(id int, name string) string {
+	if name != "" {
+		return name
+	}
+	return fmt.Sprintf("handler %d", id)
+}
+
+// describeHandler is how errors refer to a handler: by id, plus name if any.
+func describeHandler(id int, name string) string {
+	if name == "" {
+		return fmt.Sprintf("handler %d", id)
+	}
+	return fmt.Sprintf("handler %d (%s)", id, name)
+}
+
+func (s *handlerSpec) addDeps(ids ...int) {
+	for _, id := range ids {
+		if !slices.Contains(s.deps, id) {
+			s.deps = append(s.deps, id)
+		}
+	}
+}
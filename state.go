@@ -24,6 +24,15 @@ func (s *State) Read(ctx context.Context) (any, error) {
 	return s.data, s.err
 }
 
+func (s *State) ready() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
 func (s *State) Write(data any, err error) {
 	s.data, s.err = data, err
 	close(s.done)
@@ -0,0 +1,97 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type Outcome int
+
+const (
+	OutcomeUnknown Outcome = iota
+	OutcomeSuccess
+	OutcomeError
+	OutcomeSkipped
+	OutcomePanic
+	OutcomeTimeout
+	OutcomeCancelled
+	OutcomePartial
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeSuccess:
+		return "success"
+	case OutcomeError:
+		return "error"
+	case OutcomeSkipped:
+		return "skipped"
+	case OutcomePanic:
+		return "panic"
+	case OutcomeTimeout:
+		return "timeout"
+	case OutcomeCancelled:
+		return "cancelled"
+	case OutcomePartial:
+		return "partial"
+	default:
+		return "unknown"
+	}
+}
+
+func outcomeOf(err error) Outcome {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case errors.Is(err, ErrPartialResult):
+		return OutcomePartial
+	case errors.Is(err, ErrSkip):
+		return OutcomeSkipped
+	case errors.Is(err, context.DeadlineExceeded):
+		return OutcomeTimeout
+	case errors.Is(err, context.Canceled):
+		return OutcomeCancelled
+	default:
+		return OutcomeError
+	}
+}
+
+// WaitSpan is a time interval a handler spent blocked in Store.Read.
+type WaitSpan struct {
+	HandlerId int
+	Start     time.Time
+	End       time.Time
+}
+
+type HandlerStats struct {
+	HandlerId int
+	Name      string
+	Labels    map[string]string
+	Start     time.Time
+	End       time.Time
+	// Waiting is the time spent blocked on upstream handlers, Throttled is the
+	// time spent waiting for WithRateLimit, Executing is the rest. Waiting is
+	// only known for stores reading through TrackRead.
+	Waiting   time.Duration
+	Throttled time.Duration
+	Executing time.Duration
+	Waits     []WaitSpan
+	Outcome   Outcome
+	Attempts  int
+	// Winner is the attempt whose result was taken, it is set by WithHedge.
+	Winner   int
+	CacheHit bool
+	// Shared tells that WithSingleflight handed the result to more than one caller.
+	Shared bool
+	Err    error
+}
+
+// DisplayName is the handler name or its id when no name was given.
+func (s HandlerStats) DisplayName() string {
+	return displayName(s.HandlerId, s.Name)
+}
+
+func (s HandlerStats) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
@@ -0,0 +1,169 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Runner_HandlerStatistics_WaitingAndExecuting(t *testing.T) {
+	t.Parallel()
+
+	const (
+		handlerId1 = 1
+		handlerId2 = 2
+	)
+
+	handler := func(delay time.Duration) Handler[Store] {
+		return func(context.Context, Store) (any, error) {
+			time.Sleep(delay)
+			return nil, nil
+		}
+	}
+
+	s := NewStore()
+	require.NoError(t, errors.Join(s.Register(handlerId1), s.Register(handlerId2)))
+
+	r := NewRunner[Store]()
+	require.NoError(t, errors.Join(
+		r.Register(handlerId1, handler(time.Millisecond*200)),
+		r.Register(handlerId2, handler(time.Millisecond*50), WithRunAfter[Store](handlerId1)),
+	))
+	require.NoError(t, r.Run(context.Background(), s))
+
+	statistics := r.HandlerStatistics()
+	require.Len(t, statistics, 2)
+
+	upstream := statistics[handlerId1]
+	require.Equal(t, OutcomeSuccess, upstream.Outcome)
+	require.Equal(t, 1, upstream.Attempts)
+	require.Zero(t, upstream.Waiting)
+	require.GreaterOrEqual(t, upstream.Executing, time.Millisecond*200)
+
+	downstream := statistics[handlerId2]
+	require.Equal(t, OutcomeSuccess, downstream.Outcome)
+	require.GreaterOrEqual(t, downstream.Waiting, time.Millisecond*150)
+	require.GreaterOrEqual(t, downstream.Executing, time.Millisecond*50)
+	require.Less(t, downstream.Executing, time.Millisecond*150)
+	require.Len(t, downstream.Waits, 1)
+	require.Equal(t, handlerId1, downstream.Waits[0].HandlerId)
+	require.Equal(t, downstream.Duration(), downstream.Waiting+downstream.Executing)
+}
+
+func Test_Runner_HandlerStatistics_Outcome(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name    string
+		handler Handler[Store]
+		opts    []Option[Store]
+		outcome Outcome
+	}{
+		{
+			name:    "success",
+			handler: func(context.Context, Store) (any, error) { return "foobar", nil },
+			outcome: OutcomeSuccess,
+		},
+		{
+			name:    "error",
+			handler: func(context.Context, Store) (any, error) { return nil, errors.New("error in handler") },
+			outcome: OutcomeError,
+		},
+		{
+			name:    "skipped",
+			handler: func(context.Context, Store) (any, error) { return "foobar", nil },
+			opts:    []Option[Store]{WithCondition[Store](true)},
+			outcome: OutcomeSkipped,
+		},
+		{
+			name:    "panic",
+			handler: func(context.Context, Store) (any, error) { panic("panic in handler") },
+			outcome: OutcomePanic,
+		},
+		{
+			name: "timeout",
+			handler: func(ctx context.Context, _ Store) (any, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+			opts:    []Option[Store]{WithTimeout[Store](time.Millisecond * 10)},
+			outcome: OutcomeTimeout,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			const handlerId = 1
+
+			s := NewStore()
+			require.NoError(t, s.Register(handlerId))
+
+			r := NewRunner[Store]()
+			require.NoError(t, r.Register(handlerId, tc.handler, tc.opts...))
+			_ = r.Run(context.Background(), s)
+
+			stats := r.HandlerStatistics()[handlerId]
+			require.Equal(t, tc.outcome, stats.Outcome)
+			if tc.outcome == OutcomeSuccess {
+				require.NoError(t, stats.Err)
+			} else {
+				require.Error(t, stats.Err)
+			}
+		})
+	}
+}
+
+type trackingStore struct {
+	m map[int]*State
+}
+
+func (s *trackingStore) Read(ctx context.Context, id int) (any, error) {
+	return TrackRead(ctx, id, false, func() (any, error) {
+		return s.m[id].Read(ctx)
+	})
+}
+
+func (s *trackingStore) Write(id int, data any, err error) error {
+	s.m[id].Write(data, err)
+	return nil
+}
+
+func (s *trackingStore) Register(id int) error {
+	s.m[id] = NewState()
+	return nil
+}
+
+func Test_Runner_HandlerStatistics_TrackRead(t *testing.T) {
+	t.Parallel()
+
+	const (
+		handlerId1 = 1
+		handlerId2 = 2
+	)
+
+	s := &trackingStore{m: make(map[int]*State)}
+	require.NoError(t, errors.Join(s.Register(handlerId1), s.Register(handlerId2)))
+
+	r := NewRunner[*trackingStore]()
+	require.NoError(t, errors.Join(
+		r.Register(handlerId1, func(context.Context, *trackingStore) (any, error) {
+			time.Sleep(time.Millisecond * 100)
+			return nil, nil
+		}),
+		r.Register(handlerId2, func(context.Context, *trackingStore) (any, error) {
+			return nil, nil
+		}, WithRunAfter[*trackingStore](handlerId1)),
+	))
+	require.NoError(t, r.Run(context.Background(), s))
+
+	downstream := r.HandlerStatistics()[handlerId2]
+	require.GreaterOrEqual(t, downstream.Waiting, time.Millisecond*50)
+	require.Less(t, downstream.Executing, time.Millisecond*50)
+	require.Len(t, downstream.Waits, 1)
+	require.Equal(t, handlerId1, downstream.Waits[0].HandlerId)
+}
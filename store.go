@@ -4,8 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 )
 
+// Store holds the results of handlers. Implementations other than the one
+// made by NewStore should block in Read through TrackRead, otherwise the time
+// spent on upstream handlers counts as executing.
 type Store interface {
 	Read(ctx context.Context, id int) (any, error)
 	Write(id int, data any, err error) error
@@ -35,12 +39,33 @@ func (s *store) Register(id int) error {
 
 func (s *store) Read(ctx context.Context, id int) (any, error) {
 	if state, ok := s.m[id]; ok {
-		data, err := state.Read(ctx)
-		return data, err
+		return TrackRead(ctx, id, state.ready(), func() (any, error) {
+			return state.Read(ctx)
+		})
 	}
 	return nil, ErrStateNotRegistered
 }
 
+// TrackRead calls read and records the time it takes as the handler of ctx
+// waiting on handlerId, for HandlerStats, span links and CriticalPath. ready
+// tells that the state of handlerId is already written, otherwise
+// RunnerObserver.OnHandlerWaiting is called first. Outside a run it only
+// calls read.
+func TrackRead(ctx context.Context, handlerId int, ready bool, read func() (any, error)) (any, error) {
+	e, ok := executionFrom(ctx)
+	if !ok {
+		return read()
+	}
+
+	if !ready {
+		e.waiting(ctx, handlerId)
+	}
+	defer func(from time.Time) {
+		e.addWait(handlerId, from, time.Now())
+	}(time.Now())
+	return read()
+}
+
 func (s *store) Write(id int, data any, err error) error {
 	if state, ok := s.m[id]; ok {
 		state.Write(data, err)
@@ -57,7 +82,7 @@ func Read[T any](ctx context.Context, s Store, handlerId int) (T, error) {
 
 	result, ok := untyped.(T)
 	if !ok {
-		return *new(T), fmt.Errorf("invalid type %T for data from handler %d", *new(T), handlerId)
+		return *new(T), fmt.Errorf("invalid type %T for data from %s", *new(T), handlerRef(ctx, handlerId))
 	}
 
 	return result, err
@@ -0,0 +1,167 @@
+package pipes
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+type SpanContext struct {
+	TraceId string
+	SpanId  string
+}
+
+type Span interface {
+	SpanContext() SpanContext
+	SetAttribute(key string, value any)
+	AddLink(SpanContext)
+	End(err error)
+}
+
+// Tracer is a minimal bridge to a tracing backend. Start must return a context
+// carrying the new span, so spans started from it become its children.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+func WithTracer[S Store](t Tracer) RunnerOption[S] {
+	return func(r *Runner[S]) {
+		r.tracer = t
+	}
+}
+
+type spanKey struct{}
+
+func ContextWithSpan(ctx context.Context, span Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+func SpanFromContext(ctx context.Context) (Span, bool) {
+	span, ok := ctx.Value(spanKey{}).(Span)
+	return span, ok
+}
+
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, _ string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SpanContext() SpanContext { return SpanContext{} }
+func (noopSpan) SetAttribute(string, any) {}
+func (noopSpan) AddLink(SpanContext)      {}
+func (noopSpan) End(error)                {}
+
+type RecordedSpan struct {
+	Name       string
+	TraceId    string
+	SpanId     string
+	ParentId   string
+	Attributes map[string]any
+	Links      []SpanContext
+	Start      time.Time
+	End        time.Time
+	Err        error
+	Ended      bool
+}
+
+// RecordingTracer keeps spans in memory, it is meant for tests.
+type RecordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordingSpan
+}
+
+func NewRecordingTracer() *RecordingTracer {
+	return &RecordingTracer{}
+}
+
+func (t *RecordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	span := &recordingSpan{tracer: t}
+	span.data = RecordedSpan{
+		Name:       name,
+		SpanId:     randomHex(8),
+		Attributes: make(map[string]any),
+		Start:      time.Now(),
+	}
+
+	if parent, ok := SpanFromContext(ctx); ok {
+		span.data.TraceId = parent.SpanContext().TraceId
+		span.data.ParentId = parent.SpanContext().SpanId
+	}
+	if span.data.TraceId == "" {
+		span.data.TraceId = randomHex(16)
+	}
+
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+
+	return ContextWithSpan(ctx, span), span
+}
+
+func (t *RecordingTracer) Spans() []RecordedSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	result := make([]RecordedSpan, 0, len(t.spans))
+	for _, span := range t.spans {
+		result = append(result, span.snapshot())
+	}
+	return result
+}
+
+type recordingSpan struct {
+	tracer *RecordingTracer
+
+	mu   sync.Mutex
+	data RecordedSpan
+}
+
+func (s *recordingSpan) SpanContext() SpanContext {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return SpanContext{TraceId: s.data.TraceId, SpanId: s.data.SpanId}
+}
+
+func (s *recordingSpan) SetAttribute(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Attributes[key] = value
+}
+
+func (s *recordingSpan) AddLink(sc SpanContext) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Links = append(s.data.Links, sc)
+}
+
+func (s *recordingSpan) End(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data.Ended {
+		return
+	}
+	s.data.End, s.data.Err, s.data.Ended = time.Now(), err, true
+}
+
+func (s *recordingSpan) snapshot() RecordedSpan {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data := s.data
+	data.Attributes = maps.Clone(s.data.Attributes)
+	data.Links = slices.Clone(s.data.Links)
+	return data
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
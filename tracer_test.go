@@ -0,0 +1,90 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RecordingTracer(t *testing.T) {
+	t.Parallel()
+
+	tracer := NewRecordingTracer()
+
+	ctx, parent := tracer.Start(context.Background(), "parent")
+	_, child := tracer.Start(ctx, "child")
+	child.AddLink(SpanContext{TraceId: "foo", SpanId: "bar"})
+	child.SetAttribute("key", "value")
+	child.End(errors.New("error in span"))
+	parent.End(nil)
+
+	spans := tracer.Spans()
+	require.Len(t, spans, 2)
+	require.Equal(t, "parent", spans[0].Name)
+	require.Empty(t, spans[0].ParentId)
+	require.True(t, spans[0].Ended)
+
+	require.Equal(t, "child", spans[1].Name)
+	require.Equal(t, spans[0].TraceId, spans[1].TraceId)
+	require.Equal(t, spans[0].SpanId, spans[1].ParentId)
+	require.Equal(t, []SpanContext{{TraceId: "foo", SpanId: "bar"}}, spans[1].Links)
+	require.Equal(t, "value", spans[1].Attributes["key"])
+	require.ErrorContains(t, spans[1].Err, "error in span")
+}
+
+func Test_Runner_Run_WithTracer(t *testing.T) {
+	t.Parallel()
+
+	const (
+		handlerId1 = 1
+		handlerId2 = 2
+	)
+
+	s := NewStore()
+	require.NoError(t, errors.Join(s.Register(handlerId1), s.Register(handlerId2)))
+
+	tracer := NewRecordingTracer()
+	r := NewRunner(WithTracer[Store](tracer))
+	require.NoError(t, errors.Join(
+		r.Register(handlerId1, func(context.Context, Store) (any, error) {
+			return nil, errors.New("error in handler")
+		}),
+		r.Register(handlerId2, func(ctx context.Context, _ Store) (any, error) {
+			span, ok := SpanFromContext(ctx)
+			if !ok {
+				return nil, errors.New("no span in context")
+			}
+			span.SetAttribute("custom", true)
+			return nil, nil
+		}, WithRunAfter[Store](handlerId1)),
+	))
+	require.NoError(t, r.Run(context.Background(), s))
+
+	_, err := s.Read(context.Background(), handlerId2)
+	require.NoError(t, err)
+
+	spans := map[string]RecordedSpan{}
+	for _, span := range tracer.Spans() {
+		require.True(t, span.Ended)
+		spans[span.Name] = span
+	}
+	require.Len(t, spans, 3)
+
+	run := spans["run"]
+	require.Equal(t, r.RunId(), run.Attributes["run_id"])
+	require.Empty(t, run.ParentId)
+	require.NoError(t, run.Err)
+
+	upstream := spans["handler 1"]
+	require.Equal(t, run.SpanId, upstream.ParentId)
+	require.Equal(t, run.TraceId, upstream.TraceId)
+	require.Equal(t, "error", upstream.Attributes["outcome"])
+	require.ErrorContains(t, upstream.Err, "error in handler")
+
+	downstream := spans["handler 2"]
+	require.Equal(t, run.SpanId, downstream.ParentId)
+	require.Equal(t, true, downstream.Attributes["custom"])
+	require.Equal(t, []SpanContext{{TraceId: upstream.TraceId, SpanId: upstream.SpanId}}, downstream.Links)
+}
@@ -0,0 +1,73 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// WithTransform replaces the data of a successful or partial result with what
+// fn makes of it before it is written to the store. The handler fails with the
+// error of fn, if any.
+func WithTransform[S Store](fn func(any) (any, error)) Option[S] {
+	return resultOption[S](OptionDescriptor{Kind: OptionTransform, Name: "WithTransform"}, transformer(fn))
+}
+
+// WithTypedTransform is WithTransform for handlers returning In, data of
+// another type is an error. Nil data is passed as the zero In.
+func WithTypedTransform[S Store, In, Out any](fn func(In) (Out, error)) Option[S] {
+	return resultOption[S](OptionDescriptor{Kind: OptionTransform, Name: "WithTypedTransform"}, transformer(func(data any) (any, error) {
+		typed, err := asType[In](data)
+		if err != nil {
+			return nil, err
+		}
+		return fn(typed)
+	}))
+}
+
+func transformer(fn func(any) (any, error)) func(context.Context, any) (any, error) {
+	return func(ctx context.Context, data any) (any, error) {
+		transformed, err := fn(data)
+		if err != nil {
+			id, _ := HandlerIdFromContext(ctx)
+			return nil, fmt.Errorf("transform in %s: %w", handlerRef(ctx, id), err)
+		}
+		return transformed, nil
+	}
+}
+
+// resultOption passes successful and partial results of the handler through
+// fn. An error of fn replaces ErrPartialResult, so the handler counts as failed
+// and WithCriticalPath applies.
+func resultOption[S Store](descriptor OptionDescriptor, fn func(context.Context, any) (any, error)) Option[S] {
+	return Option[S]{
+		descriptor: descriptor,
+		wrap: func(next Handler[S]) Handler[S] {
+			return func(ctx context.Context, s S) (any, error) {
+				data, err := next(ctx, s)
+				if err != nil && !errors.Is(err, ErrPartialResult) {
+					return data, err
+				}
+
+				result, fErr := fn(ctx, data)
+				if fErr != nil {
+					return nil, fErr
+				}
+				return result, err
+			}
+		},
+	}
+}
+
+// asType converts data for the typed options, nil is the zero T.
+func asType[T any](data any) (T, error) {
+	if data == nil {
+		return *new(T), nil
+	}
+
+	typed, ok := data.(T)
+	if !ok {
+		return *new(T), fmt.Errorf("invalid type %T, expected %T", data, *new(T))
+	}
+	return typed, nil
+}
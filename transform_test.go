@@ -0,0 +1,118 @@
+package pipes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Runner_Run_WithTransform(t *testing.T) {
+	t.Parallel()
+
+	const (
+		handlerId1 = 1
+		handlerId2 = 2
+	)
+
+	fetch := func(context.Context, Store) (any, error) {
+		return "page", nil
+	}
+	toBytes := func(data any) (any, error) {
+		return []byte(data.(string)), nil
+	}
+
+	s := NewStore()
+	require.NoError(t, errors.Join(s.Register(handlerId1), s.Register(handlerId2)))
+
+	r := NewRunner[Store]()
+	require.NoError(t, errors.Join(
+		r.Register(handlerId1, fetch, WithTransform[Store](toBytes)),
+		r.Register(handlerId2, fetch, WithTransform[Store](func(any) (any, error) {
+			return nil, errors.New("error in transform")
+		})),
+	))
+	require.NoError(t, r.Run(context.Background(), s))
+
+	data, err := s.Read(context.Background(), handlerId1)
+	require.NoError(t, err)
+	require.Equal(t, []byte("page"), data)
+
+	data, err = s.Read(context.Background(), handlerId2)
+	require.Nil(t, data)
+	require.EqualError(t, err, "transform in handler 2: error in transform")
+}
+
+func Test_Runner_Run_WithTypedTransform(t *testing.T) {
+	t.Parallel()
+
+	const (
+		handlerId1 = 1
+		handlerId2 = 2
+	)
+
+	type page struct {
+		Title string `json:"title"`
+	}
+	parse := WithTypedTransform[Store](func(raw string) (page, error) {
+		var p page
+		err := json.Unmarshal([]byte(raw), &p)
+		return p, err
+	})
+	result := func(data any) Handler[Store] {
+		return func(context.Context, Store) (any, error) {
+			return data, nil
+		}
+	}
+
+	s := NewStore()
+	require.NoError(t, errors.Join(s.Register(handlerId1), s.Register(handlerId2)))
+
+	r := NewRunner[Store]()
+	require.NoError(t, errors.Join(
+		r.Register(handlerId1, result(`{"title":"pipes"}`), parse),
+		r.Register(handlerId2, result(42), parse),
+	))
+	require.NoError(t, r.Run(context.Background(), s))
+
+	data, err := Read[page](context.Background(), s, handlerId1)
+	require.NoError(t, err)
+	require.Equal(t, page{Title: "pipes"}, data)
+
+	_, err = s.Read(context.Background(), handlerId2)
+	require.EqualError(t, err, "transform in handler 2: invalid type int, expected string")
+}
+
+func Test_Runner_Run_WithTransform_PartialResult(t *testing.T) {
+	t.Parallel()
+
+	const handlerId = 1
+
+	s := NewStore()
+	require.NoError(t, s.Register(handlerId))
+
+	r := NewRunner[Store]()
+	require.NoError(t, r.Register(handlerId, func(ctx context.Context, _ Store) (any, error) {
+		<-SoftDeadline(ctx)
+		return "{", nil
+	},
+		WithCriticalPath[Store](),
+		WithTypedTransform[Store](func(raw string) (map[string]any, error) {
+			var m map[string]any
+			err := json.Unmarshal([]byte(raw), &m)
+			return m, err
+		}),
+		WithSoftTimeout[Store](time.Millisecond*10),
+	))
+
+	err := r.Run(context.Background(), s)
+	require.ErrorIs(t, err, ErrCriticalPath)
+
+	_, err = s.Read(context.Background(), handlerId)
+	require.ErrorContains(t, err, "transform in handler 1")
+	require.NotErrorIs(t, err, ErrPartialResult)
+	require.Equal(t, OutcomeError, r.HandlerStatistics()[handlerId].Outcome)
+}
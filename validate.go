@@ -0,0 +1,38 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrValidation = errors.New("validation failed")
+
+// WithValidate checks the data of a successful or partial result before it is
+// written to the store. A violation drops the data and fails the handler with
+// an error wrapping ErrValidation.
+func WithValidate[S Store](fn func(any) error) Option[S] {
+	return resultOption[S](OptionDescriptor{Kind: OptionValidate, Name: "WithValidate"}, validator(fn))
+}
+
+// WithTypedValidate is WithValidate for handlers returning T, data of another
+// type is a violation. Nil data is passed as the zero T.
+func WithTypedValidate[S Store, T any](fn func(T) error) Option[S] {
+	return resultOption[S](OptionDescriptor{Kind: OptionValidate, Name: "WithTypedValidate"}, validator(func(data any) error {
+		typed, err := asType[T](data)
+		if err != nil {
+			return err
+		}
+		return fn(typed)
+	}))
+}
+
+func validator(fn func(any) error) func(context.Context, any) (any, error) {
+	return func(ctx context.Context, data any) (any, error) {
+		if err := fn(data); err != nil {
+			id, _ := HandlerIdFromContext(ctx)
+			return nil, fmt.Errorf("%w in %s: %w", ErrValidation, handlerRef(ctx, id), err)
+		}
+		return data, nil
+	}
+}
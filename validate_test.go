@@ -0,0 +1,130 @@
+package pipes
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Runner_Run_WithValidate(t *testing.T) {
+	t.Parallel()
+
+	const (
+		handlerId1 = 1
+		handlerId2 = 2
+		handlerId3 = 3
+	)
+
+	notEmpty := func(data any) error {
+		if data == "" {
+			return errors.New("empty page")
+		}
+		return nil
+	}
+	page := func(data string, err error) Handler[Store] {
+		return func(context.Context, Store) (any, error) {
+			return data, err
+		}
+	}
+
+	s := NewStore()
+	require.NoError(t, errors.Join(s.Register(handlerId1), s.Register(handlerId2), s.Register(handlerId3)))
+
+	r := NewRunner[Store]()
+	require.NoError(t, errors.Join(
+		r.Register(handlerId1, page("page", nil), WithValidate[Store](notEmpty)),
+		r.Register(handlerId2, page("", nil), WithName[Store]("fetch"), WithValidate[Store](notEmpty)),
+		r.Register(handlerId3, page("", errors.New("error in handler")), WithValidate[Store](notEmpty)),
+	))
+	require.NoError(t, r.Run(context.Background(), s))
+
+	data, err := s.Read(context.Background(), handlerId1)
+	require.NoError(t, err)
+	require.Equal(t, "page", data)
+
+	data, err = s.Read(context.Background(), handlerId2)
+	require.Nil(t, data)
+	require.ErrorIs(t, err, ErrValidation)
+	require.EqualError(t, err, "validation failed in handler 2 (fetch): empty page")
+
+	_, err = s.Read(context.Background(), handlerId3)
+	require.NotErrorIs(t, err, ErrValidation)
+}
+
+func Test_Runner_Run_WithTypedValidate(t *testing.T) {
+	t.Parallel()
+
+	const (
+		handlerId1 = 1
+		handlerId2 = 2
+		handlerId3 = 3
+	)
+
+	notNil := WithTypedValidate[Store](func(m map[string]int) error {
+		if m == nil {
+			return errors.New("nil map")
+		}
+		return nil
+	})
+	result := func(data any) Handler[Store] {
+		return func(context.Context, Store) (any, error) {
+			return data, nil
+		}
+	}
+
+	s := NewStore()
+	require.NoError(t, errors.Join(s.Register(handlerId1), s.Register(handlerId2), s.Register(handlerId3)))
+
+	r := NewRunner[Store]()
+	require.NoError(t, errors.Join(
+		r.Register(handlerId1, result(map[string]int{"a": 1}), notNil),
+		r.Register(handlerId2, result(nil), notNil),
+		r.Register(handlerId3, result("a"), notNil),
+	))
+	require.NoError(t, r.Run(context.Background(), s))
+
+	_, err := s.Read(context.Background(), handlerId1)
+	require.NoError(t, err)
+
+	_, err = s.Read(context.Background(), handlerId2)
+	require.EqualError(t, err, "validation failed in handler 2: nil map")
+
+	_, err = s.Read(context.Background(), handlerId3)
+	require.EqualError(t, err, "validation failed in handler 3: invalid type string, expected map[string]int")
+}
+
+func Test_Runner_Run_WithValidate_PartialResult(t *testing.T) {
+	t.Parallel()
+
+	const handlerId = 1
+
+	s := NewStore()
+	require.NoError(t, s.Register(handlerId))
+
+	r := NewRunner[Store]()
+	require.NoError(t, r.Register(handlerId, func(ctx context.Context, _ Store) (any, error) {
+		<-SoftDeadline(ctx)
+		return "", nil
+	},
+		WithCriticalPath[Store](),
+		WithValidate[Store](func(data any) error {
+			if data == "" {
+				return errors.New("empty page")
+			}
+			return nil
+		}),
+		WithSoftTimeout[Store](time.Millisecond*10),
+	))
+
+	err := r.Run(context.Background(), s)
+	require.ErrorIs(t, err, ErrCriticalPath)
+	require.ErrorIs(t, err, ErrValidation)
+
+	_, err = s.Read(context.Background(), handlerId)
+	require.ErrorIs(t, err, ErrValidation)
+	require.NotErrorIs(t, err, ErrPartialResult)
+	require.Equal(t, OutcomeError, r.HandlerStatistics()[handlerId].Outcome)
+}